@@ -2,8 +2,12 @@ package cmd
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
@@ -13,13 +17,29 @@ import (
 	"github.com/tarantino19/restgo/internal/config"
 	"github.com/tarantino19/restgo/internal/formatter"
 	"github.com/tarantino19/restgo/internal/gemini"
+	"github.com/tarantino19/restgo/internal/gitfs"
 	"github.com/tarantino19/restgo/pkg/models"
 )
 
 var (
-	noCache bool
+	noCache       bool
+	noIndex       bool
+	jobs          int
+	contextTokens int
+	includes      []string
+	excludes      []string
+	explainSkip   bool
+	services      []string
+	apiVersions   []string
+	includeTests  bool
+	watch         bool
+	rev           string
 )
 
+// errNoAPIKey is returned by report when endpoints need summaries but no
+// Gemini API key is set
+var errNoAPIKey = errors.New("Gemini API key not set")
+
 var sumCmd = &cobra.Command{
 	Use:   "sum [directory]",
 	Short: "Analyze REST API endpoints in a directory",
@@ -32,6 +52,17 @@ for REST API endpoints and generates AI-powered summaries using Gemini API.`,
 func init() {
 	rootCmd.AddCommand(sumCmd) //viper native command AddComand
 	sumCmd.Flags().BoolVar(&noCache, "no-cache", false, "Disable cache and regenerate all summaries")
+	sumCmd.Flags().BoolVar(&noIndex, "no-index", false, "Re-analyze every directory instead of reusing the results of unchanged directories")
+	sumCmd.Flags().IntVarP(&jobs, "jobs", "j", 0, "Number of parallel analysis workers (default: number of CPUs)")
+	sumCmd.Flags().StringSliceVar(&includes, "include", nil, "Only scan files matching these globs (e.g. 'services/**/*.go')")
+	sumCmd.Flags().StringSliceVar(&excludes, "exclude", nil, "Skip paths matching these globs (e.g. '**/generated/**')")
+	sumCmd.Flags().BoolVar(&explainSkip, "explain-skip", false, "Print every skipped path and why it was skipped")
+	sumCmd.Flags().BoolVar(&includeTests, "include-tests", false, "Also scan test files and list the routes they register separately")
+	sumCmd.Flags().IntVar(&contextTokens, "context-tokens", 0, "Approximate token budget of handler code sent for each summary (default 600)")
+	sumCmd.Flags().StringSliceVar(&services, "service", nil, "Only show endpoints of these services (e.g. 'services/users')")
+	sumCmd.Flags().StringVar(&rev, "rev", "", "Analyze a git revision (tag, branch or commit) instead of the working copy")
+	sumCmd.Flags().BoolVarP(&watch, "watch", "w", false, "Keep watching the directory and refresh the endpoints when files change")
+	sumCmd.Flags().StringSliceVar(&apiVersions, "api-version", nil, "Only show endpoints of these API versions (e.g. 'v2', or 'unversioned')")
 }
 
 func runSum(cmd *cobra.Command, args []string) {
@@ -48,21 +79,48 @@ func runSum(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	// Check if directory exists
-	if _, err := os.Stat(absDir); os.IsNotExist(err) {
-		color.Red("Directory does not exist: %s", absDir)
-		os.Exit(1)
+	// Read the tree of a git revision instead of the working copy
+	var revFS *gitfs.FS
+	var projectFS fs.FS = os.DirFS(absDir)
+	repoRoot := ""
+	if rev != "" {
+		if watch {
+			color.Red("--watch cannot be combined with --rev")
+			os.Exit(1)
+		}
+
+		repoRoot, err = gitfs.Root(absDir)
+		if err != nil {
+			color.Red("Error locating git repository: %v", err)
+			os.Exit(1)
+		}
+		revFS, err = gitfs.Open(repoRoot, rev)
+		if err != nil {
+			color.Red("Error reading revision: %v", err)
+			os.Exit(1)
+		}
+		defer revFS.Close()
+
+		rel, _ := filepath.Rel(repoRoot, absDir)
+		projectFS, err = fs.Sub(revFS, filepath.ToSlash(rel))
+		if err != nil {
+			color.Red("Error reading revision: %v", err)
+			os.Exit(1)
+		}
 	}
 
-	// Check for API key
-	apiKey := config.GetAPIKey()
-	if apiKey == "" {
-		color.Red("Error: Gemini API key not set")
-		color.Yellow("Please set your API key using one of the following methods:")
-		color.Yellow("1. Run: restapisummarizer config set api-key YOUR_API_KEY")
-		color.Yellow("2. Set environment variable: export GEMINI_API_KEY=YOUR_API_KEY")
+	// Check if directory exists
+	if info, err := fs.Stat(projectFS, "."); err != nil || !info.IsDir() {
+		if revFS != nil {
+			color.Red("Directory does not exist at revision %s: %s", rev, absDir)
+		} else {
+			color.Red("Directory does not exist: %s", absDir)
+		}
 		os.Exit(1)
 	}
+	if revFS != nil {
+		color.Blue("📌 Analyzing %s at revision %s (%s)", absDir, rev, revFS.Commit()[:12])
+	}
 
 	// Initialize cache
 	endpointCache, err := cache.NewCache(24 * time.Hour) // Added 24 * time.Hour as expiration
@@ -71,34 +129,139 @@ func runSum(cmd *cobra.Command, args []string) {
 		// Continue without cache
 	}
 
+	// Load user-defined patterns from the user and project configs
+	patternConfigs, err := config.LoadPatternsFS(projectFS, absDir)
+	if err != nil {
+		color.Red("Error loading custom patterns: %v", err)
+		os.Exit(1)
+	}
+	customPatterns, err := analyzer.CompilePatterns(patternConfigs)
+	if err != nil {
+		color.Red("Error compiling custom patterns: %v", err)
+		os.Exit(1)
+	}
+
+	// Locate the file index that lets unchanged directories be skipped
+	indexPath := ""
+	if !noIndex && rev == "" {
+		indexPath, err = analyzer.IndexPath(absDir)
+		if err != nil {
+			color.Yellow("Warning: Could not locate file index: %v", err)
+			// Continue without index
+		}
+	} else if watch {
+		// Watching always reuses the results of unchanged directories, within the session
+		indexDir, err := os.MkdirTemp("", "restapisummarizer-index")
+		if err == nil {
+			defer os.RemoveAll(indexDir)
+			indexPath = filepath.Join(indexDir, "index.json")
+		}
+	}
+
 	// Create analyzer
-	analyzer := analyzer.NewAnalyzer()
+	opts := analyzer.Options{
+		Jobs:           jobs,
+		Include:        includes,
+		Exclude:        excludes,
+		ExplainSkip:    explainSkip,
+		IncludeTests:   includeTests,
+		ContextTokens:  contextTokens,
+		CustomPatterns: customPatterns,
+		Index:          indexPath,
+	}
+	if revFS != nil {
+		opts.FS = revFS
+		opts.FSRoot = repoRoot
+	}
+	analyzer := analyzer.NewAnalyzer(opts)
 
+	// Summaries generated while watching, by endpoint and code
+	var known map[string]string
+	if watch {
+		known = make(map[string]string)
+	}
+
+	if err := report(analyzer, absDir, endpointCache, known); err != nil {
+		printReportError(err)
+		if !watch {
+			os.Exit(1)
+		}
+	}
+
+	if watch {
+		watchDirectory(analyzer, absDir, endpointCache, known)
+	}
+}
+
+// report analyzes dir, summarizes the endpoints lacking documentation and
+// prints them. Summaries in known, keyed by endpoint and code, are reused
+// without calling Gemini, and new ones are added to it.
+func report(analyzer *analyzer.Analyzer, dir string, endpointCache *cache.Cache, known map[string]string) error {
 	// Analyze directory
 	color.Green("\n🚀 Starting REST API analysis...\n")
 	startTime := time.Now()
 
-	endpoints, err := analyzer.AnalyzeDirectory(absDir)
+	endpoints, err := analyzer.AnalyzeDirectory(dir)
 	if err != nil {
-		color.Red("Error analyzing directory: %v", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to analyze directory: %w", err)
+	}
+
+	// Keep only the requested services
+	if len(services) > 0 {
+		endpoints = filterByService(endpoints, services)
 	}
 
+	// Keep only the requested API versions
+	if len(apiVersions) > 0 {
+		endpoints = filterByVersion(endpoints, apiVersions)
+	}
+
+	// Merge duplicate detections and find colliding routes among the
+	// endpoints shown
+	endpoints, conflicts := analyzer.PostProcess(endpoints)
+
 	if len(endpoints) == 0 {
-		color.Yellow("No REST API endpoints found in %s", absDir)
+		color.Yellow("No REST API endpoints found in %s", dir)
 		color.Yellow("Make sure the directory contains source code with REST API definitions.")
-		return
+		return nil
+	}
+
+	// Endpoints documented in code keep their hand-written summaries, and
+	// routes registered in tests are listed without one
+	var undocumented []*models.Endpoint
+	docsCount := 0
+	for _, endpoint := range endpoints {
+		if endpoint.SummarySource == models.SummaryFromDocs {
+			docsCount++
+		} else if !endpoint.Test {
+			undocumented = append(undocumented, endpoint)
+		}
 	}
 
 	// Check cache for existing summaries
 	var needsSummary []*models.Endpoint
 	cachedCount := 0
 
+	if known != nil {
+		var unknown []*models.Endpoint
+		for _, endpoint := range undocumented {
+			if summary, found := known[summaryKey(endpoint)]; found {
+				endpoint.Summary = summary
+				endpoint.SummarySource = models.SummaryFromAI
+				cachedCount++
+			} else {
+				unknown = append(unknown, endpoint)
+			}
+		}
+		undocumented = unknown
+	}
+
 	if endpointCache != nil && !noCache {
-		for _, endpoint := range endpoints {
+		for _, endpoint := range undocumented {
 			fileHash := cache.HashFile(endpoint.RawCode)
 			if summary, found := endpointCache.Get(endpoint.Method, endpoint.Path, fileHash); found {
 				endpoint.Summary = summary
+				endpoint.SummarySource = models.SummaryFromAI
 				cachedCount++
 			} else {
 				needsSummary = append(needsSummary, endpoint)
@@ -109,17 +272,22 @@ func runSum(cmd *cobra.Command, args []string) {
 			color.Blue("📦 Using %d cached summaries\n", cachedCount)
 		}
 	} else {
-		needsSummary = endpoints
+		needsSummary = undocumented
 	}
 
 	// Generate summaries only for endpoints that need them
 	if len(needsSummary) > 0 {
+		// Check for API key, needed only for endpoints without documentation
+		apiKey := config.GetAPIKey()
+		if apiKey == "" {
+			return errNoAPIKey
+		}
+
 		// Create Gemini client
 		color.Blue("🤖 Initializing Gemini AI...\n")
 		geminiClient, err := gemini.NewClient(apiKey)
 		if err != nil {
-			color.Red("Error creating Gemini client: %v", err)
-			os.Exit(1)
+			return err
 		}
 		defer geminiClient.Close()
 
@@ -136,6 +304,11 @@ func runSum(cmd *cobra.Command, args []string) {
 			color.Red("Error generating summaries: %v", err)
 			// Continue anyway - we can still show endpoints without summaries
 		}
+		for _, endpoint := range needsSummary {
+			if endpoint.Summary != "" && endpoint.Summary != "Summary unavailable" {
+				endpoint.SummarySource = models.SummaryFromAI
+			}
+		}
 
 		// Cache the new summaries
 		if endpointCache != nil && !noCache {
@@ -148,20 +321,90 @@ func runSum(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	// Remember the summaries for the next refresh
+	if known != nil {
+		for _, endpoint := range endpoints {
+			if endpoint.SummarySource == models.SummaryFromAI {
+				known[summaryKey(endpoint)] = endpoint.Summary
+			}
+		}
+	}
+
 	// Display results
 	formatter.FormatEndpointsTable(endpoints)
+	formatter.FormatVersions(endpoints)
+	formatter.FormatConflicts(conflicts)
 
 	// Show statistics
 	duration := time.Since(startTime)
 	color.Green("\n✅ Analysis completed in %s\n", duration.Round(time.Second))
 
+	if docsCount > 0 {
+		color.HiBlack("   • %d/%d summaries from code documentation", docsCount, len(endpoints))
+	}
+
 	if cachedCount > 0 {
 		percentage := (cachedCount * 100) / len(endpoints)
 		color.HiBlack("   • %d/%d summaries from cache (%d%%)", cachedCount, len(endpoints), percentage)
 	}
 
 	if len(needsSummary) > 0 {
-		tokensEstimate := len(needsSummary) * 50 // Rough estimate
+		tokensEstimate := gemini.EstimateTokens(needsSummary)
 		color.HiBlack("   • Generated %d new summaries (~%d tokens used)", len(needsSummary), tokensEstimate)
 	}
+	return nil
+}
+
+// printReportError prints an error returned by report, explaining how to set
+// the API key when it is missing
+func printReportError(err error) {
+	color.Red("Error: %v", err)
+	if errors.Is(err, errNoAPIKey) {
+		color.Yellow("Please set your API key using one of the following methods:")
+		color.Yellow("1. Run: restapisummarizer config set api-key YOUR_API_KEY")
+		color.Yellow("2. Set environment variable: export GEMINI_API_KEY=YOUR_API_KEY")
+	}
+}
+
+// summaryKey identifies the summary of an endpoint's current code
+func summaryKey(endpoint *models.Endpoint) string {
+	return endpoint.Method + " " + endpoint.Path + " " + cache.HashFile(endpoint.RawCode)
+}
+
+// filterByService keeps the endpoints belonging to one of the given services
+func filterByService(endpoints []*models.Endpoint, services []string) []*models.Endpoint {
+	var filtered []*models.Endpoint
+	for _, endpoint := range endpoints {
+		for _, service := range services {
+			if endpoint.Service == service {
+				filtered = append(filtered, endpoint)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// filterByVersion keeps the endpoints served in one of the given API
+// versions; "unversioned" selects endpoints without a version
+func filterByVersion(endpoints []*models.Endpoint, versions []string) []*models.Endpoint {
+	wanted := make(map[string]bool)
+	for _, version := range versions {
+		if strings.EqualFold(version, "unversioned") {
+			wanted[""] = true
+		} else {
+			wanted[analyzer.NormalizeVersion(version)] = true
+		}
+	}
+
+	var filtered []*models.Endpoint
+	for _, endpoint := range endpoints {
+		for _, version := range strings.Split(endpoint.Version, ",") {
+			if wanted[version] {
+				filtered = append(filtered, endpoint)
+				break
+			}
+		}
+	}
+	return filtered
 }
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/fsnotify/fsnotify"
+	"github.com/tarantino19/restgo/internal/analyzer"
+	"github.com/tarantino19/restgo/internal/cache"
+)
+
+// watchDebounce is how long the tree must stay quiet after a change before
+// it is analyzed again, so that a burst of saves triggers a single refresh
+const watchDebounce = 500 * time.Millisecond
+
+// watchDirectory refreshes the endpoints of dir whenever a file that can
+// affect them changes, until interrupted. Unchanged directories are reused
+// from the file index and only endpoints whose code changed are summarized.
+func watchDirectory(a *analyzer.Analyzer, dir string, endpointCache *cache.Cache, known map[string]string) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		color.Red("Error starting file watcher: %v", err)
+		os.Exit(1)
+	}
+	defer watcher.Close()
+
+	// Watch every scanned directory, including the ones created later
+	watched := make(map[string]bool)
+	watchDirs := func() {
+		dirs, err := a.WatchDirs(dir)
+		if err != nil {
+			color.Yellow("Warning: Could not list directories to watch: %v", err)
+		}
+		for _, d := range dirs {
+			if watched[d] {
+				continue
+			}
+			if err := watcher.Add(d); err != nil {
+				color.Yellow("Warning: Could not watch %s: %v", d, err)
+				continue
+			}
+			watched[d] = true
+		}
+	}
+	watchDirs()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	color.Blue("\n👀 Watching %s for changes (Ctrl+C to stop)", dir)
+
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+	var changed []string
+
+	for {
+		select {
+		case <-ctx.Done():
+			color.Green("\n👋 Stopped watching %s", dir)
+			return
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			color.Yellow("Warning: File watcher error: %v", err)
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+
+			switch {
+			case watched[event.Name] && (event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename)):
+				// The watch of a removed directory is dropped with it
+				delete(watched, event.Name)
+			case event.Has(fsnotify.Create) && isDir(event.Name):
+				// Picked up by watchDirs before the refresh
+			case !a.Relevant(dir, event.Name):
+				continue
+			}
+			changed = append(changed, event.Name)
+			debounce.Reset(watchDebounce)
+
+		case <-debounce.C:
+			// Clear the terminal and show the refreshed endpoints
+			fmt.Print("\033[H\033[2J")
+			color.Blue("🔄 Changed: %s", describeChanges(dir, changed))
+			changed = nil
+
+			watchDirs()
+			if err := report(a, dir, endpointCache, known); err != nil {
+				// Keep watching; the next change may fix it
+				printReportError(err)
+			}
+			color.Blue("\n👀 Watching %s for changes (Ctrl+C to stop)", dir)
+		}
+	}
+}
+
+// describeChanges lists the changed paths relative to dir, abbreviating
+// long lists
+func describeChanges(dir string, paths []string) string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, path := range paths {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			rel = path
+		}
+		if !seen[rel] {
+			seen[rel] = true
+			names = append(names, rel)
+		}
+	}
+
+	if len(names) > 3 {
+		return fmt.Sprintf("%s and %d more", strings.Join(names[:3], ", "), len(names)-3)
+	}
+	return strings.Join(names, ", ")
+}
+
+// isDir reports whether path is an existing directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
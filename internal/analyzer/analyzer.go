@@ -3,166 +3,449 @@ package analyzer
 import (
 	"bufio"
 	"fmt"
+	"io/fs"
 	"os" // Keep os for os.ReadFile
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/fatih/color"
+	"github.com/tarantino19/restgo/pkg/detector"
 	"github.com/tarantino19/restgo/pkg/models"
 )
 
+// Options configures an Analyzer
+type Options struct {
+	Jobs         int      // Number of directories analyzed in parallel (defaults to the number of CPUs)
+	Include      []string // Only scan files matching one of these globs
+	Exclude      []string // Never scan paths matching one of these globs
+	ExplainSkip  bool     // Report every skipped path and the reason it was skipped
+	IncludeTests bool     // Scan test files and directories, marking their endpoints as tests
+
+	ContextTokens int // Approximate token budget for each endpoint's code (defaults to defaultContextTokens)
+
+	Index string // File index reused across runs to skip unchanged directories, "" to analyze every file
+
+	FS     fs.FS  // Tree read instead of the OS filesystem, such as a git revision; the index is not used with it
+	FSRoot string // Directory FS stands in for, treated as the repository root
+
+	CustomPatterns []FrameworkPatterns // User-defined patterns merged with the built-ins
+	Detectors      []detector.Detector // Detectors used in addition to the built-in and registered ones
+}
+
 // Analyzer handles the analysis of source code files
 type Analyzer struct {
-	patterns []FrameworkPatterns
+	detectors     []detector.Detector
+	modules       *moduleResolver
+	jobs          int
+	include       []string
+	exclude       []string
+	explainSkip   bool
+	includeTests  bool
+	contextTokens int
+	indexPath     string
+	fingerprint   string
+	index         *fileIndex
+	files         fileSystem
 }
 
+// defaultContextTokens is the default token budget for the code of an endpoint
+const defaultContextTokens = 600
+
 // NewAnalyzer creates a new analyzer instance
-func NewAnalyzer() *Analyzer {
+func NewAnalyzer(opts Options) *Analyzer {
+	jobs := opts.Jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	contextTokens := opts.ContextTokens
+	if contextTokens <= 0 {
+		contextTokens = defaultContextTokens
+	}
+
+	var files fileSystem = osFileSystem{}
+	indexPath := opts.Index
+	if opts.FS != nil {
+		// Trees without modification times cannot be checked against the index
+		files = rootedFS{root: opts.FSRoot, fsys: opts.FS}
+		indexPath = ""
+	}
+
+	patterns := MergePatterns(GetAllPatterns(), opts.CustomPatterns)
+	detectors := []detector.Detector{
+		NewRegexDetector(patterns),
+		NewGraphQLDetector(),
+		NewProtoDetector(),
+		newOpenAPIDetector(files),
+		newServerlessDetector(files),
+	}
+	detectors = append(detectors, detector.Registered()...)
+	detectors = append(detectors, opts.Detectors...)
+
 	return &Analyzer{
-		patterns: GetAllPatterns(),
+		detectors:     detectors,
+		jobs:          jobs,
+		include:       opts.Include,
+		exclude:       opts.Exclude,
+		explainSkip:   opts.ExplainSkip,
+		includeTests:  opts.IncludeTests,
+		contextTokens: contextTokens,
+		indexPath:     indexPath,
+		fingerprint:   analysisFingerprint(detectors, patterns, contextTokens),
+		files:         files,
 	}
 }
 
-// AnalyzeDirectory scans a directory for REST API endpoints
+// skippedPath is a path excluded from the scan and the reason why
+type skippedPath struct {
+	path   string
+	reason string
+}
+
+// dirJob is a directory whose supported files are analyzed together,
+// numbered in the order the walk finishes with it
+type dirJob struct {
+	index   int
+	dir     string
+	paths   []string
+	skipped []skippedPath
+}
+
+// dirResult holds the outcome of analyzing a directory
+type dirResult struct {
+	*dirJob
+	endpoints []*models.Endpoint
+	types     map[string]*models.Schema // Data types declared in the directory
+	errs      []error
+	reused    bool // Endpoints were taken from the file index
+}
+
+// AnalyzeDirectory scans a directory for REST API endpoints.
+// Directories are handed to a pool of workers as soon as the walk is done
+// with them; results are reassembled in that order so output is deterministic.
 func (a *Analyzer) AnalyzeDirectory(dir string) ([]*models.Endpoint, error) {
-	var endpoints []*models.Endpoint
-	var filesAnalyzed int
-	var currentDir string
+	a.modules = newModuleResolver(a.files, dir)
+	a.index = nil
+	if a.indexPath != "" {
+		a.index = loadIndex(a.indexPath, a.fingerprint)
+	}
 
-	color.Blue("🔍 Scanning directory tree: %s", dir)
-	color.Blue("This will recursively scan all subdirectories...\n")
+	ignore, err := newIgnoreMatcher(a.files, dir, a.include, a.exclude)
+	if err != nil {
+		return nil, err
+	}
 
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+	color.Blue("🔍 Scanning directory tree: %s", dir)
+	color.Blue("This will recursively scan all subdirectories (%d workers)...\n", a.jobs)
+
+	jobs := make(chan *dirJob, a.jobs*4)
+	results := make(chan dirResult, a.jobs*4)
+
+	// Walk the tree, grouping candidate files by directory
+	var walkErr error
+	var proxyConfigs []string
+	go func() {
+		defer close(jobs)
+
+		var open []*dirJob // Directories still being walked, outermost first
+		index := 0
+		send := func(job *dirJob) {
+			if len(job.paths) > 0 || len(job.skipped) > 0 {
+				job.index = index
+				index++
+				jobs <- job
+			}
 		}
-
-		// Track current directory for progress display
-		dir := filepath.Dir(path)
-		if dir != currentDir && info.IsDir() && !strings.HasPrefix(info.Name(), ".") {
-			currentDir = dir
-			relPath, _ := filepath.Rel(dir, path)
-			if relPath != "." {
-				color.HiBlack("  📂 Entering: %s", path)
+		// closeDirs sends every open directory that does not contain dir
+		closeDirs := func(dir string) {
+			for len(open) > 0 && !isWithin(dir, open[len(open)-1].dir) {
+				send(open[len(open)-1])
+				open = open[:len(open)-1]
 			}
 		}
 
-		// Skip directories and hidden files
-		if info.IsDir() || strings.HasPrefix(info.Name(), ".") {
-			return nil
-		}
+		walkErr = a.files.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
 
-		// Skip common non-source directories
-		if shouldSkipPath(path) {
-			return nil
-		}
+			parent := filepath.Dir(path)
+			closeDirs(parent)
 
-		// Check if file matches any framework patterns
-		ext := filepath.Ext(path)
-		for _, framework := range a.patterns {
-			for _, filePattern := range framework.FilePatterns {
-				if ext == filePattern {
-					filesAnalyzed++
-					fileEndpoints, err := a.analyzeFile(path, framework)
-					if err != nil {
-						color.Yellow("Warning: Error analyzing %s: %v", path, err)
-						continue
-					}
-					endpoints = append(endpoints, fileEndpoints...)
-					break
+			var size int64
+			if !d.IsDir() {
+				if info, err := d.Info(); err == nil {
+					size = info.Size()
 				}
 			}
-		}
 
-		return nil
-	})
+			if reason := a.skipReason(ignore, dir, path, d.IsDir(), size); reason != "" {
+				if a.explainSkip && len(open) > 0 {
+					top := open[len(open)-1]
+					top.skipped = append(top.skipped, skippedPath{path: path, reason: reason})
+				}
+				// Prune skipped directories instead of descending into them
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
 
-	if err != nil {
-		return nil, fmt.Errorf("error walking directory: %w", err)
-	}
+			if d.IsDir() {
+				ignore.enterDir(path)
+				open = append(open, &dirJob{dir: path})
+				return nil
+			}
 
-	color.Green("\n✓ Scan complete! Analyzed %d files, found %d endpoints", filesAnalyzed, len(endpoints))
-	return endpoints, nil
-}
+			if isProxyConfig(path) {
+				proxyConfigs = append(proxyConfigs, path)
+			}
 
-// analyzeFile analyzes a single file for endpoints
-func (a *Analyzer) analyzeFile(filePath string, framework FrameworkPatterns) ([]*models.Endpoint, error) {
-	content, err := os.ReadFile(filePath) // Changed from ioutil.ReadFile
-	if err != nil {
-		return nil, err
+			// Check if any detector wants the file
+			if !a.supports(path) {
+				return nil
+			}
+
+			top := open[len(open)-1]
+			top.paths = append(top.paths, path)
+			return nil
+		})
+
+		closeDirs("")
+	}()
+
+	// Analyze directories in parallel
+	var wg sync.WaitGroup
+	for i := 0; i < a.jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				results <- a.analyzeDir(job)
+			}
+		}()
 	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
 
+	// Collect results in order; all progress output happens here
 	var endpoints []*models.Endpoint
-	lines := strings.Split(string(content), "\n")
-
-	for lineNum, line := range lines {
-		for _, pattern := range framework.Patterns {
-			matches := pattern.Regex.FindStringSubmatch(line)
-			if matches != nil {
-				endpoint := a.extractEndpoint(matches, pattern, filePath, lineNum+1, lines, framework.Name)
-				if endpoint != nil {
-					endpoints = append(endpoints, endpoint)
+	types := make(map[string]map[string]*models.Schema) // By service, then name
+	var filesAnalyzed, dirsReused int
+	pending := make(map[int]dirResult)
+	next := 0
+	cwd, _ := os.Getwd()
+
+	for result := range results {
+		pending[result.index] = result
+		for {
+			result, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+
+			for _, skipped := range result.skipped {
+				relPath, _ := filepath.Rel(dir, skipped.path)
+				color.HiBlack("  ⏭  Skipped %s: %s", relPath, skipped.reason)
+			}
+			if len(result.paths) == 0 {
+				continue
+			}
+
+			filesAnalyzed += len(result.paths)
+			if result.reused {
+				dirsReused++
+			}
+			color.HiBlack("  📂 Entering: %s", result.dir)
+			for _, err := range result.errs {
+				color.Yellow("Warning: %v", err)
+			}
+
+			counts := make(map[string]int)
+			for _, endpoint := range result.endpoints {
+				counts[endpoint.File]++
+			}
+			for _, path := range result.paths {
+				if counts[path] > 0 {
+					// Show relative path for better visibility of subdirectories
+					relPath, _ := filepath.Rel(cwd, path)
+					color.Cyan("  ✓ Found %d endpoints in %s", counts[path], relPath)
+				}
+			}
+			endpoints = append(endpoints, result.endpoints...)
+
+			service := a.modules.serviceFor(result.dir)
+			if types[service] == nil {
+				types[service] = make(map[string]*models.Schema)
+			}
+			for name, schema := range result.types {
+				if _, ok := types[service][name]; !ok {
+					types[service][name] = schema
 				}
 			}
 		}
 	}
 
-	if len(endpoints) > 0 {
-		// Show relative path for better visibility of subdirectories
-		cwd, _ := os.Getwd()
-		relPath, _ := filepath.Rel(cwd, filePath)
-		color.Cyan("  ✓ Found %d endpoints in %s", len(endpoints), relPath)
+	if walkErr != nil {
+		return nil, fmt.Errorf("error walking directory: %w", walkErr)
+	}
+
+	color.Green("\n✓ Scan complete! Analyzed %d files, found %d endpoints", filesAnalyzed, len(endpoints))
+
+	if a.index != nil {
+		if dirsReused > 0 {
+			color.Blue("📦 Reused the results of %d unchanged directories from the file index", dirsReused)
+		}
+		if err := a.index.save(); err != nil {
+			color.Yellow("Warning: %v", err)
+		}
+	}
+
+	// Resolve request and response types declared in other directories
+	resolveSchemas(endpoints, types)
+
+	// Map in-code paths to the URLs exposed by Ingress rules and nginx proxies
+	routes, errs := readProxyRoutes(a.files, proxyConfigs)
+	for _, err := range errs {
+		color.Yellow("Warning: %v", err)
+	}
+	if len(routes) > 0 {
+		mapped := assignPublicURLs(endpoints, routes)
+		color.Blue("🌐 Mapped %d endpoints to public URLs using %d ingress and proxy routes", mapped, len(routes))
 	}
 
 	return endpoints, nil
 }
 
-// extractEndpoint extracts endpoint information from regex matches
-func (a *Analyzer) extractEndpoint(matches []string, pattern Pattern, filePath string, lineNum int, lines []string, framework string) *models.Endpoint {
-	var method, path string
+// skipReason returns why the scan of root skips path, or "" if it is scanned
+func (a *Analyzer) skipReason(ignore *ignoreMatcher, root, path string, isDir bool, size int64) string {
+	reason := ignore.skipReason(path, isDir, size)
+	if reason == "" && !a.includeTests && path != root {
+		rel, _ := filepath.Rel(root, path)
+		reason = testSkipReason(filepath.ToSlash(rel), isDir)
+	}
+	return reason
+}
+
+// supports reports whether any detector wants to inspect the file
+func (a *Analyzer) supports(path string) bool {
+	for _, d := range a.detectors {
+		if d.Supports(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// analyzeDir reads the directory's files and runs every detector over the
+// files it supports. Endpoints are ordered by file, in walk order.
+func (a *Analyzer) analyzeDir(job *dirJob) dirResult {
+	result := dirResult{dirJob: job}
+	if len(job.paths) == 0 {
+		return result
+	}
 
-	// Extract method and path based on pattern configuration
-	if pattern.MethodIndex > 0 && pattern.MethodIndex < len(matches) {
-		method = strings.ToUpper(matches[pattern.MethodIndex])
+	deps := a.modules.dependenciesFor(job.dir)
+	context := ""
+	if a.index != nil {
+		context = analysisContext(deps, a.modules.serviceFor(job.dir))
+		if endpoints, types, ok := a.index.lookup(a.files, job.dir, job.paths, context); ok {
+			result.endpoints = endpoints
+			result.types = types
+			result.reused = true
+			return result
+		}
 	}
 
-	if pattern.PathIndex > 0 && pattern.PathIndex < len(matches) {
-		path = matches[pattern.PathIndex]
+	order := make(map[string]int)
+	infos := make(map[string]os.FileInfo)
+	var files []detector.File
+	for _, path := range job.paths {
+		// Stat before reading so that a later change is never indexed as seen
+		info, err := a.files.Stat(path)
+		if err != nil {
+			result.errs = append(result.errs, fmt.Errorf("error reading %s: %w", path, err))
+			continue
+		}
+		infos[path] = info
+		content, err := a.files.ReadFile(path)
+		if err != nil {
+			result.errs = append(result.errs, fmt.Errorf("error reading %s: %w", path, err))
+			continue
+		}
+		order[path] = len(files)
+		files = append(files, detector.File{Path: path, Content: content, Dependencies: deps})
 	}
 
-	// Handle special cases
-	if pattern.MethodIndex == 0 && strings.Contains(lines[lineNum-1], "resources") {
-		// Rails resources generates multiple endpoints
-		return &models.Endpoint{
-			Method:    "RESOURCE",
-			Path:      "/" + path,
-			File:      filePath,
-			Line:      lineNum,
-			Framework: framework,
-			Language:  getLanguageFromExtension(filepath.Ext(filePath)),
-			RawCode:   extractCodeContext(lines, lineNum-1, 3),
+	result.types = packageTypes(files)
+
+	for _, d := range a.detectors {
+		var supported []detector.File
+		for _, file := range files {
+			if d.Supports(file.Path) {
+				supported = append(supported, file)
+			}
 		}
+		if len(supported) == 0 {
+			continue
+		}
+
+		detected, err := d.Detect(supported)
+		if err != nil {
+			result.errs = append(result.errs, fmt.Errorf("%s detector failed in %s: %w", d.Name(), job.dir, err))
+		}
+		result.endpoints = append(result.endpoints, detected...)
 	}
 
-	// Default to GET if method not found (e.g., Flask without methods specified)
-	if method == "" && path != "" {
-		method = "GET"
+	for _, endpoint := range result.endpoints {
+		if endpoint.Kind == "" {
+			endpoint.Kind = models.KindREST
+		}
+		if endpoint.Source == "" {
+			endpoint.Source = models.SourceCode
+		}
+		if endpoint.Service == "" {
+			endpoint.Service = a.modules.serviceFor(filepath.Dir(endpoint.File))
+		}
+		if rel, err := filepath.Rel(a.modules.root, endpoint.File); err == nil {
+			endpoint.Test = isTestFile(filepath.ToSlash(rel))
+		}
+		endpoint.RawCode = limitContext(endpoint.RawCode, a.contextTokens)
 	}
 
-	// Skip if we couldn't extract both method and path
-	if method == "" || path == "" {
-		return nil
+	sort.SliceStable(result.endpoints, func(i, j int) bool {
+		return fileOrder(order, result.endpoints[i].File) < fileOrder(order, result.endpoints[j].File)
+	})
+
+	if a.index != nil {
+		if len(result.errs) == 0 {
+			a.index.store(job.dir, files, infos, context, result.endpoints, result.types)
+		} else {
+			a.index.forget(job.dir)
+		}
 	}
 
-	return &models.Endpoint{
-		Method:    method,
-		Path:      path,
-		File:      filePath,
-		Line:      lineNum,
-		Framework: framework,
-		Language:  getLanguageFromExtension(filepath.Ext(filePath)),
-		RawCode:   extractCodeContext(lines, lineNum-1, 5),
+	return result
+}
+
+// fileOrder returns the walk position of path, placing unknown files last
+func fileOrder(order map[string]int, path string) int {
+	if idx, ok := order[path]; ok {
+		return idx
 	}
+	return len(order)
+}
+
+// isWithin reports whether path is dir or lies inside it
+func isWithin(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
 }
 
 // extractCodeContext extracts surrounding code for context
@@ -189,69 +472,26 @@ func extractCodeContext(lines []string, centerLine int, contextSize int) string
 	return strings.Join(context, "\n")
 }
 
-// shouldSkipPath checks if a path should be skipped
-func shouldSkipPath(path string) bool {
-	skipDirs := []string{
-		"node_modules",
-		"vendor",
-		".git",
-		"dist",
-		"build",
-		"target",
-		"__pycache__",
-		".venv",
-		"venv",
-		"env",
-		".idea",
-		".vscode",
-		"coverage",
-		"test",
-		"tests",
-		"spec",
-		"specs",
-		".next",
-		"out",
-		"tmp",
-		"temp",
-		"cache",
-		".cache",
-		"logs",
-		"docs",
-		"documentation",
-		"examples",
-		"migrations",
-		"public",
-		"static",
-		"assets",
-		"bin",
-		"obj",
-	}
-
-	// Check if file is too large (skip files over 1MB)
-	if info, err := os.Stat(path); err == nil && info.Size() > 1024*1024 {
-		return true
+// limitContext truncates code to whole lines fitting an approximate token
+// budget, counting four characters per token
+func limitContext(code string, tokens int) string {
+	budget := tokens * 4
+	if len(code) <= budget {
+		return code
 	}
 
-	// Check each part of the path
-	parts := strings.Split(path, string(os.PathSeparator))
-	for _, part := range parts {
-		for _, skip := range skipDirs {
-			if part == skip {
-				return true
+	lines := strings.Split(code, "\n")
+	size := 0
+	for i, line := range lines {
+		size += len(line) + 1
+		if size > budget {
+			if i == 0 {
+				return string([]rune(line)[:min(budget, len([]rune(line)))]) + " ..."
 			}
-		}
-		// Skip hidden directories
-		if strings.HasPrefix(part, ".") && part != "." && part != ".." {
-			return true
+			return strings.Join(lines[:i], "\n") + "\n..."
 		}
 	}
-
-	// Skip minified files
-	if strings.Contains(path, ".min.") {
-		return true
-	}
-
-	return false
+	return code
 }
 
 // getLanguageFromExtension returns the language based on file extension
@@ -0,0 +1,282 @@
+package analyzer
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+var (
+	swaggoTagRegex      = regexp.MustCompile(`^@(\w+)\s+(.*)$`)
+	swaggoParamRegex    = regexp.MustCompile(`^(\S+)\s+(\w+)\s+(\S+)\s+(true|false)(?:\s+"([^"]*)")?(.*)$`)
+	swaggoResultRegex   = regexp.MustCompile(`^(\d{3})\S*(?:\s+\{(\w+)\}\s+(\S+))?(?:\s+"([^"]*)")?`)
+	swaggoDefaultRegex  = regexp.MustCompile(`default\(([^)]*)\)`)
+	annotationCallRegex = regexp.MustCompile(`@(\w+)\s*\(`)
+	javaAttrRegex       = regexp.MustCompile(`\b(\w+)\s*=\s*("(?:[^"\\]|\\.)*"|\{[^}]*\}|[\w.]+)`)
+	quotedStringRegex   = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"`)
+)
+
+// extractAPIDocs merges swaggo comment blocks and springdoc/springfox
+// annotations into the endpoint: summary, description, tags, parameters,
+// responses and security requirements
+func extractAPIDocs(endpoint *models.Endpoint, src *sourceFile, h *handler, pkg *sourcePackage) {
+	switch endpoint.Framework {
+	case "Spring":
+		routeLine := endpoint.Line - 1
+		if classLine := enclosingClass(src, routeLine); classLine >= 0 {
+			springDocs(endpoint, classAnnotations(src.lines, classLine), true, pkg)
+		}
+		springDocs(endpoint, annotationBlock(src.lines, routeLine), false, pkg)
+	default:
+		for _, block := range docBlocks(endpoint, src, h) {
+			swaggoDocs(endpoint, block, pkg)
+		}
+	}
+
+	endpoint.AuthRequired = requiresAuth(endpoint.Middleware)
+}
+
+// swaggoDocs reads swaggo annotations such as @Param and @Success from
+// comment lines
+func swaggoDocs(endpoint *models.Endpoint, lines []string, pkg *sourcePackage) {
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !isCommentLine(trimmed) {
+			continue
+		}
+		matches := swaggoTagRegex.FindStringSubmatch(stripCommentMarkers(trimmed))
+		if matches == nil {
+			continue
+		}
+		value := strings.TrimSpace(matches[2])
+
+		switch strings.ToLower(matches[1]) {
+		case "summary":
+			endpoint.Summary = value
+			endpoint.SummarySource = models.SummaryFromDocs
+		case "description":
+			endpoint.Description = strings.TrimSpace(endpoint.Description + " " + value)
+		case "tags":
+			for _, tag := range strings.Split(value, ",") {
+				addTag(endpoint, strings.TrimSpace(tag))
+			}
+		case "param":
+			param := swaggoParamRegex.FindStringSubmatch(value)
+			if param == nil {
+				continue
+			}
+			switch param[2] {
+			case "body":
+				if endpoint.Request == nil {
+					endpoint.Request = pkg.schemaFor(param[3])
+				}
+			case models.ParamPath, models.ParamQuery, models.ParamHeader, models.ParamCookie:
+				parsed := models.Parameter{
+					Name:        param[1],
+					In:          param[2],
+					Type:        param[3],
+					Required:    param[4] == "true",
+					Description: param[5],
+				}
+				if def := swaggoDefaultRegex.FindStringSubmatch(param[6]); def != nil {
+					parsed.Default = def[1]
+				}
+				endpoint.Parameters = addParameter(endpoint.Parameters, parsed)
+			}
+		case "success", "failure", "response":
+			result := swaggoResultRegex.FindStringSubmatch(value)
+			if result == nil {
+				continue
+			}
+			status, _ := strconv.Atoi(result[1])
+			addResponse(endpoint, status, result[4], result[3], pkg)
+		case "security":
+			endpoint.Middleware = uniqueStrings(append(endpoint.Middleware, "@Security "+value))
+		}
+	}
+}
+
+// springDocs reads springdoc (@Operation, @ApiResponse, @Tag, @Parameter)
+// and springfox (@ApiOperation, @Api, @ApiParam) annotations. Only tags and
+// security requirements apply when reading the annotations of the class.
+func springDocs(endpoint *models.Endpoint, block string, class bool, pkg *sourcePackage) {
+	for _, loc := range annotationCallRegex.FindAllStringSubmatchIndex(block, -1) {
+		name := block[loc[2]:loc[3]]
+		attrs := javaAttributes(balancedText(block, loc[1]-1))
+
+		switch name {
+		case "Tag":
+			addTag(endpoint, attrs["name"])
+		case "Api":
+			for _, tag := range quotedStrings(attrs["tags"]) {
+				addTag(endpoint, tag)
+			}
+		case "SecurityRequirement":
+			endpoint.Middleware = uniqueStrings(append(endpoint.Middleware, collapseSpace(block[loc[0]:loc[1]]+balancedText(block, loc[1]-1)+")")))
+		}
+		if class {
+			continue
+		}
+
+		switch name {
+		case "Operation", "ApiOperation":
+			summary := attrs["summary"]
+			if name == "ApiOperation" {
+				summary = attrs["value"]
+			}
+			if summary != "" {
+				endpoint.Summary = summary
+				endpoint.SummarySource = models.SummaryFromDocs
+			}
+			if description := attrs["description"] + attrs["notes"]; description != "" {
+				endpoint.Description = description
+			}
+			for _, tag := range quotedStrings(attrs["tags"]) {
+				addTag(endpoint, tag)
+			}
+		case "ApiResponse":
+			code := attrs["responseCode"] + attrs["code"]
+			status, err := strconv.Atoi(code)
+			if err != nil {
+				continue
+			}
+			addResponse(endpoint, status, attrs["description"]+attrs["message"], strings.TrimSuffix(attrs["implementation"]+attrs["response"], ".class"), pkg)
+		case "Parameter", "ApiImplicitParam":
+			// Standalone declarations name their parameter; inline ones are read with the signature
+			if attrs["name"] == "" {
+				continue
+			}
+			in := strings.ToLower(attrs["in"] + attrs["paramType"])
+			if idx := strings.LastIndex(in, "."); idx >= 0 {
+				in = in[idx+1:]
+			}
+			switch in {
+			case models.ParamPath, models.ParamQuery, models.ParamHeader, models.ParamCookie:
+			default:
+				continue
+			}
+			endpoint.Parameters = addParameter(endpoint.Parameters, models.Parameter{
+				Name:        attrs["name"],
+				In:          in,
+				Type:        attrs["dataType"],
+				Required:    attrs["required"] == "true" || in == models.ParamPath,
+				Description: attrs["description"] + attrs["value"],
+			})
+		}
+	}
+}
+
+// javaAttributes parses the name = value attributes of an annotation, with
+// a leading positional string stored as "value". Nested annotations
+// contribute their attributes unless already set by the outer one.
+func javaAttributes(args string) map[string]string {
+	attrs := make(map[string]string)
+	trimmed := strings.TrimSpace(args)
+	if strings.HasPrefix(trimmed, `"`) {
+		if matches := quotedStringRegex.FindStringSubmatch(trimmed); matches != nil {
+			attrs["value"] = matches[1]
+		}
+	}
+
+	for _, matches := range javaAttrRegex.FindAllStringSubmatch(args, -1) {
+		if _, ok := attrs[matches[1]]; ok {
+			continue
+		}
+		value := matches[2]
+		if strings.HasPrefix(value, `"`) {
+			value = strings.ReplaceAll(value[1:len(value)-1], `\"`, `"`)
+		}
+		attrs[matches[1]] = value
+	}
+	return attrs
+}
+
+// quotedStrings returns the string literals in a value such as {"a", "b"}
+func quotedStrings(value string) []string {
+	if !strings.Contains(value, `"`) {
+		if value == "" {
+			return nil
+		}
+		return []string{value}
+	}
+	var values []string
+	for _, matches := range quotedStringRegex.FindAllStringSubmatch(value, -1) {
+		values = append(values, matches[1])
+	}
+	return values
+}
+
+// balancedText returns the text inside the parentheses opened at open
+func balancedText(text string, open int) string {
+	depth := 0
+	var quote byte
+	for i := open; i < len(text); i++ {
+		c := text[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'':
+			quote = c
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return text[open+1 : i]
+			}
+		}
+	}
+	return text[open+1:]
+}
+
+// addTag adds a tag to the endpoint unless already present
+func addTag(endpoint *models.Endpoint, tag string) {
+	if tag != "" {
+		endpoint.Tags = uniqueStrings(append(endpoint.Tags, tag))
+	}
+}
+
+// addResponse records a documented response and its status code. A
+// documented success body fills in a response schema not found in code.
+func addResponse(endpoint *models.Endpoint, status int, description, schema string, pkg *sourcePackage) {
+	found := false
+	for i := range endpoint.Responses {
+		if endpoint.Responses[i].Status == status {
+			found = true
+			if endpoint.Responses[i].Description == "" {
+				endpoint.Responses[i].Description = description
+			}
+			if endpoint.Responses[i].Schema == "" {
+				endpoint.Responses[i].Schema = schema
+			}
+		}
+	}
+	if !found {
+		endpoint.Responses = append(endpoint.Responses, models.Response{Status: status, Description: description, Schema: schema})
+		sort.Slice(endpoint.Responses, func(i, j int) bool {
+			return endpoint.Responses[i].Status < endpoint.Responses[j].Status
+		})
+	}
+
+	known := false
+	for _, code := range endpoint.StatusCodes {
+		known = known || code == status
+	}
+	if !known {
+		endpoint.StatusCodes = append(endpoint.StatusCodes, status)
+		sort.Ints(endpoint.StatusCodes)
+	}
+
+	if endpoint.Response == nil && schema != "" && status >= 200 && status < 300 {
+		endpoint.Response = pkg.schemaFor(schema)
+	}
+}
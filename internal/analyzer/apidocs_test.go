@@ -0,0 +1,56 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSpringClassDocs(t *testing.T) {
+	endpoints := analyzeTree(t, map[string]string{
+		"pom.xml": `<project><dependencies><dependency><artifactId>spring-boot-starter-web</artifactId></dependency></dependencies></project>`,
+		"src/main/java/OrderController.java": `package com.example;
+
+@RestController
+@Tag(name = "orders")
+public class OrderController {
+    @Tag(name = "admin")
+    @SecurityRequirement(name = "bearerAuth")
+    @GetMapping("/orders/all")
+    public List<Order> all() {
+        return List.of();
+    }
+
+    @GetMapping("/orders/{id}")
+    public Order get(@PathVariable String id) {
+        return null;
+    }
+}
+`,
+	})
+
+	tests := []struct {
+		route      string
+		tags       string
+		middleware int
+		auth       bool
+	}{
+		{"GET /orders/all", "orders,admin", 1, true},
+		// The annotations of the first method are not class-level docs
+		{"GET /orders/{id}", "orders", 0, false},
+	}
+
+	for _, tt := range tests {
+		endpoint := endpoints[tt.route]
+		if endpoint == nil {
+			t.Errorf("%s not detected", tt.route)
+			continue
+		}
+		if tags := strings.Join(endpoint.Tags, ","); tags != tt.tags {
+			t.Errorf("%s: tags %q, want %q", tt.route, tags, tt.tags)
+		}
+		if len(endpoint.Middleware) != tt.middleware || endpoint.AuthRequired != tt.auth {
+			t.Errorf("%s: middleware %v, auth %v; want %d middleware, auth %v",
+				tt.route, endpoint.Middleware, endpoint.AuthRequired, tt.middleware, tt.auth)
+		}
+	}
+}
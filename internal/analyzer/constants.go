@@ -0,0 +1,235 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Route path expressions made of constants, concatenations and template
+// strings, captured by patterns with PathExpression set. They start with an
+// identifier (or an f-string) so they never overlap the literal patterns.
+const (
+	jsPathExpr   = `([A-Za-z_$][\w.$]*(?:\s*\+\s*(?:'[^']*'|"[^"]*"|` + "`[^`]*`" + `|[A-Za-z_$][\w.$]*))*)`
+	goPathExpr   = `([A-Za-z_][\w.]*(?:\s*\+\s*(?:"[^"]*"|` + "`[^`]*`" + `|[A-Za-z_][\w.]*))*)`
+	pyPathExpr   = `((?:[A-Za-z_][\w.]*|f"[^"]*"|f'[^']*')(?:\s*\+\s*(?:"[^"]*"|'[^']*'|f"[^"]*"|f'[^']*'|[A-Za-z_][\w.]*))*)`
+	javaPathExpr = `([A-Za-z_][\w.]*(?:\s*\+\s*(?:"[^"]*"|[A-Za-z_][\w.]*))*)`
+)
+
+var (
+	constDeclRegex  = regexp.MustCompile(`^\s*(?:export\s+)?(?:(?:const|let|var|final|static|public|private|protected|internal|readonly)\s+)*(?:(?:string|String)\s+)?([A-Za-z_$][\w$]*)\s*(?:string\s*)?(?::\s*\w+\s*)?(?::=|=)\s*(.+?)\s*;?\s*$`)
+	constValueRegex = regexp.MustCompile(`^(?:f?'[^']*'|f?"[^"]*"|` + "`[^`]*`" + `|[A-Za-z_$][\w.$]*)(?:\s*\+\s*(?:f?'[^']*'|f?"[^"]*"|` + "`[^`]*`" + `|[A-Za-z_$][\w.$]*))*$`)
+	templateRegex   = regexp.MustCompile(`\$\{\s*([^}]+?)\s*\}`)
+	fStringRegex    = regexp.MustCompile(`\{\{|\}\}|\{\s*([^{}]+?)\s*\}`)
+)
+
+// constTable maps string constants to their source expressions. Names
+// assigned different values are ambiguous and never resolved.
+type constTable map[string]string
+
+// ambiguousConst marks a name declared with conflicting values
+const ambiguousConst = "\x00"
+
+// add records a declaration, marking the name ambiguous on conflict
+func (t constTable) add(name, expr string) {
+	if existing, ok := t[name]; ok && existing != expr {
+		t[name] = ambiguousConst
+		return
+	}
+	t[name] = expr
+}
+
+// declaredConstants collects the string constants and simple concatenations
+// declared at the top level of a file. Assignments inside functions are
+// local to them and never resolved.
+func declaredConstants(src *sourceFile) constTable {
+	table := make(constTable)
+	depths := braceDepths(src.lines)
+	for i, line := range src.lines {
+		if !topLevelDepth(src.language, line, depths[i]) {
+			continue
+		}
+		matches := constDeclRegex.FindStringSubmatch(line)
+		if matches == nil || !constValueRegex.MatchString(matches[2]) || !strings.ContainsAny(matches[2], "'\"`") {
+			continue
+		}
+		table.add(matches[1], matches[2])
+	}
+	return table
+}
+
+// topLevelDepth reports whether a declaration at the given brace depth is
+// outside any function: module scope, or a class body for Java and C#,
+// whose constants are static fields
+func topLevelDepth(language, line string, depth int) bool {
+	switch language {
+	case "Python":
+		return indentation(line) == 0
+	case "Java":
+		return depth == 1
+	case "C#":
+		// Classes may sit in a namespace block
+		return depth == 1 || depth == 2
+	}
+	return depth == 0
+}
+
+// braceDepths returns the depth of curly braces at the start of each line,
+// ignoring braces in strings and line comments
+func braceDepths(lines []string) []int {
+	depths := make([]int, len(lines))
+	depth := 0
+	var quote rune
+
+	for i, line := range lines {
+		depths[i] = depth
+		runes := []rune(line)
+		for j := 0; j < len(runes); j++ {
+			c := runes[j]
+			if quote != 0 {
+				if c == '\\' && quote != '`' {
+					j++
+				} else if c == quote {
+					quote = 0
+				}
+				continue
+			}
+
+			switch c {
+			case '"', '\'', '`':
+				quote = c
+			case '/':
+				if j+1 < len(runes) && runes[j+1] == '/' {
+					j = len(runes)
+				}
+			case '{':
+				depth++
+			case '}':
+				depth = max(depth-1, 0)
+			}
+		}
+		// Only backtick strings span lines
+		if quote != '`' {
+			quote = 0
+		}
+	}
+	return depths
+}
+
+// resolveRoutePath turns a route path into a literal path. Expressions are
+// evaluated against the constants of the file, then of the package;
+// template placeholders in literal paths are substituted. Parts that cannot
+// be resolved are kept as {?name}.
+func (pkg *sourcePackage) resolveRoutePath(file, path string, expression bool) string {
+	resolver := &constResolver{pkg: pkg, file: pkg.files[file], visiting: make(map[string]bool)}
+	if expression {
+		return resolver.expression(path)
+	}
+	if strings.Contains(path, "${") {
+		return resolver.template(path)
+	}
+	return path
+}
+
+// constResolver evaluates path expressions, guarding against cycles
+type constResolver struct {
+	pkg      *sourcePackage
+	file     *sourceFile
+	visiting map[string]bool
+}
+
+// expression evaluates a concatenation of literals, templates and constants
+func (r *constResolver) expression(expr string) string {
+	var b strings.Builder
+	for _, part := range splitConcat(expr) {
+		switch {
+		case len(part) >= 2 && (part[0] == '"' || part[0] == '\''):
+			b.WriteString(part[1 : len(part)-1])
+		case len(part) >= 2 && part[0] == '`':
+			b.WriteString(r.template(part[1 : len(part)-1]))
+		case len(part) >= 3 && part[0] == 'f' && (part[1] == '"' || part[1] == '\''):
+			b.WriteString(r.fString(part[2 : len(part)-1]))
+		default:
+			b.WriteString(r.constant(part))
+		}
+	}
+	return b.String()
+}
+
+// template substitutes ${name} placeholders of a JavaScript template literal
+func (r *constResolver) template(text string) string {
+	return templateRegex.ReplaceAllStringFunc(text, func(match string) string {
+		return r.constant(templateRegex.FindStringSubmatch(match)[1])
+	})
+}
+
+// fString substitutes {name} placeholders of a Python f-string
+func (r *constResolver) fString(text string) string {
+	return fStringRegex.ReplaceAllStringFunc(text, func(match string) string {
+		switch match {
+		case "{{":
+			return "{"
+		case "}}":
+			return "}"
+		}
+		return r.constant(fStringRegex.FindStringSubmatch(match)[1])
+	})
+}
+
+// constant resolves a constant by name, trying a qualified name such as
+// config.BASE by its last segment too
+func (r *constResolver) constant(name string) string {
+	candidates := []string{name}
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		candidates = append(candidates, name[idx+1:])
+	}
+
+	for _, candidate := range candidates {
+		expr, ok := r.lookup(candidate)
+		if !ok || r.visiting[candidate] {
+			continue
+		}
+		r.visiting[candidate] = true
+		value := r.expression(expr)
+		delete(r.visiting, candidate)
+		return value
+	}
+	return "{?" + name + "}"
+}
+
+// lookup finds the expression assigned to name in the file, then the package
+func (r *constResolver) lookup(name string) (string, bool) {
+	if r.file != nil {
+		if expr, ok := r.file.constants[name]; ok {
+			return expr, expr != ambiguousConst
+		}
+	}
+	expr, ok := r.pkg.constants[name]
+	return expr, ok && expr != ambiguousConst
+}
+
+// splitConcat splits an expression on "+" outside string literals
+func splitConcat(expr string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'', '`':
+			quote = c
+		case '+':
+			parts = append(parts, strings.TrimSpace(expr[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(expr[start:]))
+}
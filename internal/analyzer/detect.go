@@ -0,0 +1,96 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// detectedFramework is a framework selected for a file along with the
+// version declared in the nearest manifest, if any
+type detectedFramework struct {
+	FrameworkPatterns
+	Version string
+}
+
+// detectFrameworks selects the frameworks that apply to a file.
+// Frameworks imported by the file itself win; otherwise frameworks declared
+// as dependencies in the surrounding manifests are used. When neither
+// identifies a framework, every framework matching the extension is applied.
+// User-defined frameworks and those without detection hints always apply.
+func detectFrameworks(path string, content []byte, frameworks []FrameworkPatterns, deps dependencies) []detectedFramework {
+	ext := filepath.Ext(path)
+
+	var candidates, byImport, byDependency, always []detectedFramework
+	for _, framework := range frameworks {
+		if !matchesExtension(framework, ext) {
+			continue
+		}
+
+		detected := detectedFramework{
+			FrameworkPatterns: framework,
+			Version:           dependencyVersion(framework, deps),
+		}
+
+		if framework.Custom || (len(framework.Imports) == 0 && len(framework.Dependencies) == 0) {
+			always = append(always, detected)
+			continue
+		}
+		candidates = append(candidates, detected)
+
+		if importsFramework(content, framework) {
+			byImport = append(byImport, detected)
+		} else if hasDependency(framework, deps) {
+			byDependency = append(byDependency, detected)
+		}
+	}
+
+	selected := byImport
+	if len(selected) == 0 {
+		selected = byDependency
+	}
+	if len(selected) == 0 {
+		selected = candidates
+	}
+
+	return append(selected, always...)
+}
+
+// matchesExtension reports whether the framework handles files with ext
+func matchesExtension(framework FrameworkPatterns, ext string) bool {
+	for _, filePattern := range framework.FilePatterns {
+		if ext == filePattern {
+			return true
+		}
+	}
+	return false
+}
+
+// importsFramework reports whether the file content imports the framework
+func importsFramework(content []byte, framework FrameworkPatterns) bool {
+	for _, re := range framework.Imports {
+		if re.Match(content) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDependency reports whether any manifest declares the framework
+func hasDependency(framework FrameworkPatterns, deps dependencies) bool {
+	for _, name := range framework.Dependencies {
+		if _, ok := deps[strings.ToLower(name)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// dependencyVersion returns the declared version of the framework, if any
+func dependencyVersion(framework FrameworkPatterns, deps dependencies) string {
+	for _, name := range framework.Dependencies {
+		if version := deps[strings.ToLower(name)]; version != "" {
+			return version
+		}
+	}
+	return ""
+}
@@ -0,0 +1,202 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+var (
+	docTagRegex     = regexp.MustCompile(`^@(\w+)\s*(.*)$`)
+	xmlSummaryRegex = regexp.MustCompile(`(?s)<summary>(.*?)</summary>`)
+	xmlSeeRegex     = regexp.MustCompile(`<see\s+(?:cref|langword)="(?:\w:)?([^"]+)"\s*/>`)
+	xmlTagRegex     = regexp.MustCompile(`<[^>]+>`)
+	sentenceRegex   = regexp.MustCompile(`^(.+?[.!?])(?:\s|$)`)
+)
+
+// extractDocSummary uses the handler's docstring, doc comment or swaggo
+// @Summary annotation as the endpoint summary
+func extractDocSummary(endpoint *models.Endpoint, src *sourceFile, h *handler) {
+	name := endpoint.Function
+	if h != nil {
+		name = h.name
+	}
+
+	var text string
+	if h != nil && h.file.language == "Python" {
+		text = pythonDocstring(h)
+	}
+
+	for _, block := range docBlocks(endpoint, src, h) {
+		if text != "" {
+			break
+		}
+		text = docComment(docLines(block, name))
+	}
+
+	if summary := firstSentence(text, name); summary != "" {
+		endpoint.Summary = summary
+		endpoint.SummarySource = models.SummaryFromDocs
+	}
+}
+
+// docBlocks returns the lines that may document an endpoint, most specific
+// first: the comments above a named handler, then the comments and
+// annotations around the route declaration
+func docBlocks(endpoint *models.Endpoint, src *sourceFile, h *handler) [][]string {
+	var blocks [][]string
+	routeLine := endpoint.Line - 1
+
+	if h != nil && h.start != routeLine && !decoratorFrameworks[endpoint.Framework] {
+		blocks = append(blocks, h.file.lines[leadingLines(h.file.lines, h.start):h.start])
+	}
+
+	end := routeLine
+	if h != nil && h.file == src && h.start > routeLine && decoratorFrameworks[endpoint.Framework] {
+		end = h.start
+	}
+	return append(blocks, src.lines[leadingLines(src.lines, routeLine):end])
+}
+
+// pythonDocstring returns the docstring opening the body of a Python handler
+func pythonDocstring(h *handler) string {
+	bodyStart := h.start + strings.Count(h.signature, "\n") + 1
+	if bodyStart > h.end {
+		return ""
+	}
+	text := strings.TrimSpace(strings.Join(h.file.lines[bodyStart:h.end+1], "\n"))
+	text = strings.TrimLeft(text, "rRuU")
+
+	for _, quote := range []string{`"""`, `'''`} {
+		if strings.HasPrefix(text, quote) {
+			if end := strings.Index(text[len(quote):], quote); end >= 0 {
+				return text[len(quote) : len(quote)+end]
+			}
+		}
+	}
+	return ""
+}
+
+// docLines keeps the lines of a block written in a documentation form:
+// /** */ blocks, /// XML docs, tags such as swaggo's @Summary and Go doc
+// comments starting with the function name. Plain comments, such as lint
+// directives, banners and commented-out code, are not documentation.
+func docLines(lines []string, name string) []string {
+	var doc []string
+	inBlock, inGoDoc := false, false
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case inBlock || strings.HasPrefix(trimmed, "/**"):
+			inBlock = !strings.HasSuffix(trimmed, "*/")
+			doc = append(doc, line)
+		case strings.HasPrefix(trimmed, "///"):
+			doc = append(doc, line)
+		case strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "#"):
+			text := stripCommentMarkers(trimmed)
+			if name != "" && strings.HasPrefix(text, name+" ") {
+				inGoDoc = true
+			}
+			if inGoDoc || docTagRegex.MatchString(text) {
+				doc = append(doc, line)
+			}
+		default:
+			inGoDoc = false
+		}
+	}
+	return doc
+}
+
+// docComment extracts the description from comment lines, preferring a
+// @Summary or @Description tag and the <summary> of C# XML docs
+func docComment(lines []string) string {
+	var text []string
+	var description string
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !isCommentLine(trimmed) && !strings.HasSuffix(trimmed, "*/") {
+			// Annotations between the comments
+			continue
+		}
+		trimmed = stripCommentMarkers(trimmed)
+
+		if matches := docTagRegex.FindStringSubmatch(trimmed); matches != nil {
+			switch strings.ToLower(matches[1]) {
+			case "summary":
+				return matches[2]
+			case "description":
+				if description == "" {
+					description = matches[2]
+				}
+			}
+			// Tags such as @param end the free-form description
+			text = append(text, "")
+			continue
+		}
+		text = append(text, trimmed)
+	}
+
+	joined := strings.Join(text, "\n")
+	if matches := xmlSummaryRegex.FindStringSubmatch(joined); matches != nil {
+		return xmlTagRegex.ReplaceAllString(xmlSeeRegex.ReplaceAllString(matches[1], "$1"), "")
+	}
+	if description != "" {
+		return description
+	}
+
+	// Keep the first paragraph
+	var paragraph []string
+	for _, line := range text {
+		if line == "" || strings.HasPrefix(line, "TODO") || strings.HasPrefix(line, "FIXME") {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+	return xmlTagRegex.ReplaceAllString(strings.Join(paragraph, " "), "")
+}
+
+// stripCommentMarkers removes the comment syntax around a line
+func stripCommentMarkers(line string) string {
+	line = strings.TrimSuffix(line, "*/")
+	for _, marker := range []string{"///", "//", "/**", "/*", "*", "#"} {
+		if strings.HasPrefix(line, marker) {
+			line = line[len(marker):]
+			break
+		}
+	}
+	return strings.TrimSpace(line)
+}
+
+// firstSentence returns the first sentence of a description. A leading
+// handler name, as in Go doc comments, is dropped.
+func firstSentence(text, name string) string {
+	text = collapseSpace(text)
+	if text == "" {
+		return ""
+	}
+
+	if name != "" {
+		if rest, ok := strings.CutPrefix(text, name+" "); ok && rest != "" {
+			runes := []rune(rest)
+			runes[0] = unicode.ToUpper(runes[0])
+			text = string(runes)
+		}
+	}
+
+	// swaggo marks handlers with "name godoc" before its annotations
+	if strings.EqualFold(text, "godoc") {
+		return ""
+	}
+
+	if matches := sentenceRegex.FindStringSubmatch(text); matches != nil {
+		return matches[1]
+	}
+	return text
+}
@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+// enrichEndpoint fills in what can be learned from the endpoint's handler
+func enrichEndpoint(endpoint *models.Endpoint, pkg *sourcePackage) {
+	src, ok := pkg.files[endpoint.File]
+	if !ok {
+		return
+	}
+
+	h := locateHandler(endpoint, src, pkg)
+	if h != nil {
+		if endpoint.Function == "" {
+			endpoint.Function = h.name
+		}
+		if code := handlerSource(endpoint, src, h); code != "" {
+			endpoint.RawCode = code
+		}
+	}
+
+	applyRoutePrefix(endpoint, src, pkg)
+	detectMiddleware(endpoint, h, src, pkg)
+	detectVersion(endpoint, src)
+	extractDocSummary(endpoint, src, h)
+	if h != nil {
+		extractSchemas(endpoint, h, pkg)
+	}
+	extractParameters(endpoint, h)
+	if h != nil && endpoint.Kind == "" {
+		extractStatusCodes(endpoint, src, h)
+	}
+	detectKind(endpoint, h)
+	extractAPIDocs(endpoint, src, h, pkg)
+}
+
+// handlerSource returns the code describing an endpoint: its route
+// declaration, the comments above the handler and the handler body.
+// It returns "" when the handler body could not be located.
+func handlerSource(endpoint *models.Endpoint, src *sourceFile, h *handler) string {
+	routeLine := endpoint.Line - 1
+	if h.start == routeLine && h.end == routeLine {
+		return ""
+	}
+
+	// Decorated and inline handlers follow the route declaration directly
+	if h.start == routeLine || decoratorFrameworks[endpoint.Framework] {
+		return strings.Join(src.lines[leadingLines(src.lines, routeLine):h.end+1], "\n")
+	}
+
+	return strings.TrimSpace(src.lines[routeLine]) + "\n\n" + strings.Join(h.file.lines[leadingLines(h.file.lines, h.start):h.end+1], "\n")
+}
+
+// leadingLines returns the first line of the comments, decorators and
+// annotations directly above line
+func leadingLines(lines []string, line int) int {
+	start := line
+	for i := line - 1; i >= 0; i-- {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || !(isCommentLine(trimmed) || annotationLnRegex.MatchString(lines[i]) || strings.HasSuffix(trimmed, "*/")) {
+			break
+		}
+		start = i
+	}
+	return start
+}
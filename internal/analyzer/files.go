@@ -0,0 +1,119 @@
+package analyzer
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fileSystem is how the analyzer reads the scanned tree, by absolute OS
+// path, so that trees other than the working copy can be analyzed
+type fileSystem interface {
+	ReadFile(path string) ([]byte, error)
+	ReadHead(path string, n int) ([]byte, error) // Up to the first n bytes of a file
+	Stat(path string) (fs.FileInfo, error)
+	ReadDir(path string) ([]fs.DirEntry, error)
+	WalkDir(root string, fn fs.WalkDirFunc) error
+	Root() string // Directory above which nothing can be read, "" if unbounded
+}
+
+// osFileSystem reads the OS filesystem
+type osFileSystem struct{}
+
+func (osFileSystem) ReadFile(path string) ([]byte, error)       { return os.ReadFile(path) }
+func (osFileSystem) Stat(path string) (fs.FileInfo, error)      { return os.Stat(path) }
+func (osFileSystem) ReadDir(path string) ([]fs.DirEntry, error) { return os.ReadDir(path) }
+func (osFileSystem) Root() string                               { return "" }
+
+func (osFileSystem) ReadHead(path string, n int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readHead(f, n)
+}
+
+func (osFileSystem) WalkDir(root string, fn fs.WalkDirFunc) error {
+	return filepath.WalkDir(root, fn)
+}
+
+// rootedFS reads an fs.FS standing in for the OS directory root, such as
+// the tree of a git revision in place of the repository's working copy
+type rootedFS struct {
+	root string
+	fsys fs.FS
+}
+
+func (r rootedFS) ReadFile(path string) ([]byte, error) {
+	name, err := r.name("read", path)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(r.fsys, name)
+}
+
+func (r rootedFS) ReadHead(path string, n int) ([]byte, error) {
+	name, err := r.name("read", path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := r.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readHead(f, n)
+}
+
+func (r rootedFS) Stat(path string) (fs.FileInfo, error) {
+	name, err := r.name("stat", path)
+	if err != nil {
+		return nil, err
+	}
+	return fs.Stat(r.fsys, name)
+}
+
+func (r rootedFS) ReadDir(path string) ([]fs.DirEntry, error) {
+	name, err := r.name("readdir", path)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadDir(r.fsys, name)
+}
+
+func (r rootedFS) Root() string {
+	return r.root
+}
+
+// WalkDir walks the FS from root, reporting OS paths to fn
+func (r rootedFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	name, err := r.name("walk", root)
+	if err != nil {
+		return err
+	}
+	return fs.WalkDir(r.fsys, name, func(p string, d fs.DirEntry, err error) error {
+		return fn(filepath.Join(r.root, filepath.FromSlash(p)), d, err)
+	})
+}
+
+// name converts an OS path below root to a path of the FS
+func (r rootedFS) name(op, path string) (string, error) {
+	rel, err := filepath.Rel(r.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", &fs.PathError{Op: op, Path: path, Err: fs.ErrNotExist}
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// readHead reads up to the first n bytes of r
+func readHead(r io.Reader, n int) ([]byte, error) {
+	head := make([]byte, n)
+	read, err := io.ReadFull(r, head)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return head[:read], err
+}
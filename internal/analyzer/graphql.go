@@ -0,0 +1,185 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/tarantino19/restgo/pkg/detector"
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+var (
+	gqlTypeRegex     = regexp.MustCompile(`^\s*(?:extend\s+)?type\s+(\w+)[^{]*\{`)
+	gqlSchemaRegex   = regexp.MustCompile(`(?s)\bschema\s*(?:@\w+\s*)*\{([^}]*)\}`)
+	gqlRootRegex     = regexp.MustCompile(`\b(query|mutation|subscription)\s*:\s*(\w+)`)
+	gqlFieldRegex    = regexp.MustCompile(`^\s*(\w+)\s*[(:]`)
+	gqlArgumentRegex = regexp.MustCompile(`(\w+)\s*:\s*([\w\[\]!]+)(?:\s*=\s*("[^"]*"|[^,)\s@]+))?`)
+	gqlReturnRegex   = regexp.MustCompile(`\)?\s*:\s*([\w\[\]!]+)\s*(?:@.*)?$`)
+)
+
+// GraphQLDetector lists the queries, mutations and subscriptions declared
+// in GraphQL schema files
+type GraphQLDetector struct{}
+
+// NewGraphQLDetector creates a detector for .graphql schema files
+func NewGraphQLDetector() *GraphQLDetector {
+	return &GraphQLDetector{}
+}
+
+// Name implements detector.Detector
+func (d *GraphQLDetector) Name() string {
+	return "graphql"
+}
+
+// Supports reports whether path is a GraphQL schema file
+func (d *GraphQLDetector) Supports(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".graphql", ".graphqls", ".gql":
+		return true
+	}
+	return false
+}
+
+// Detect returns an endpoint for every field of the root operation types
+func (d *GraphQLDetector) Detect(files []detector.File) ([]*models.Endpoint, error) {
+	var endpoints []*models.Endpoint
+	for _, file := range files {
+		endpoints = append(endpoints, graphQLOperations(file)...)
+	}
+	return endpoints, nil
+}
+
+// graphQLOperations parses the root operation types of a schema file,
+// honoring a schema { query: ... } block that renames them
+func graphQLOperations(file detector.File) []*models.Endpoint {
+	content := string(file.Content)
+	roots := map[string]string{"Query": "QUERY", "Mutation": "MUTATION", "Subscription": "SUBSCRIPTION"}
+	if matches := gqlSchemaRegex.FindStringSubmatch(content); matches != nil {
+		for _, root := range gqlRootRegex.FindAllStringSubmatch(matches[1], -1) {
+			roots[root[2]] = strings.ToUpper(root[1])
+		}
+	}
+
+	var endpoints []*models.Endpoint
+	lines := strings.Split(content, "\n")
+	operation := ""
+	depth := 0
+	inDescription := false
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if operation == "" {
+			if matches := gqlTypeRegex.FindStringSubmatch(line); matches != nil {
+				operation = roots[matches[1]]
+				if operation == "" {
+					// Skip the body of other types
+					depth = strings.Count(line, "{") - strings.Count(line, "}")
+					for depth > 0 && i+1 < len(lines) {
+						i++
+						depth += strings.Count(lines[i], "{") - strings.Count(lines[i], "}")
+					}
+				}
+			}
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if strings.Count(trimmed, `"""`)%2 == 1 {
+			inDescription = !inDescription
+			continue
+		}
+		if inDescription {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "}") {
+			operation = ""
+			continue
+		}
+		matches := gqlFieldRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		// Arguments may span several lines
+		end := i
+		for depth = strings.Count(line, "(") - strings.Count(line, ")"); depth > 0 && end+1 < len(lines); {
+			end++
+			depth += strings.Count(lines[end], "(") - strings.Count(lines[end], ")")
+		}
+		declaration := strings.Join(lines[i:end+1], "\n")
+
+		endpoint := &models.Endpoint{
+			Kind:      models.KindGraphQL,
+			Method:    operation,
+			Path:      matches[1],
+			File:      file.Path,
+			Line:      i + 1,
+			Function:  matches[1],
+			Language:  "GraphQL",
+			Framework: "GraphQL",
+			RawCode:   strings.Join(lines[graphQLDescriptionStart(lines, i):end+1], "\n"),
+		}
+		if open := strings.Index(declaration, "("); open >= 0 && open < strings.Index(declaration+":", ":") {
+			for _, arg := range gqlArgumentRegex.FindAllStringSubmatch(balancedText(declaration, open), -1) {
+				endpoint.Parameters = append(endpoint.Parameters, models.Parameter{
+					Name:     arg[1],
+					In:       models.ParamArgument,
+					Type:     arg[2],
+					Required: strings.HasSuffix(arg[2], "!") && arg[3] == "",
+					Default:  literalValue(arg[3]),
+				})
+			}
+		}
+		if ret := gqlReturnRegex.FindStringSubmatch(strings.TrimSpace(lines[end])); ret != nil {
+			endpoint.Response = &models.Schema{Name: ret[1]}
+		}
+		if summary := firstSentence(graphQLDescription(lines, i), ""); summary != "" {
+			endpoint.Summary = summary
+			endpoint.SummarySource = models.SummaryFromDocs
+		}
+
+		endpoints = append(endpoints, endpoint)
+		i = end
+	}
+
+	return endpoints
+}
+
+// graphQLDescriptionStart returns the index of the first line of the
+// description or comments above the field at line
+func graphQLDescriptionStart(lines []string, line int) int {
+	start := line
+	inBlock := false
+	for i := line - 1; i >= 0; i-- {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case inBlock:
+			if strings.HasPrefix(trimmed, `"""`) {
+				inBlock = false
+			}
+		case strings.HasSuffix(trimmed, `"""`) && !(len(trimmed) > 6 && strings.HasPrefix(trimmed, `"""`)):
+			inBlock = true
+		case strings.HasPrefix(trimmed, `"`) || strings.HasPrefix(trimmed, "#"):
+		default:
+			return start
+		}
+		start = i
+	}
+	return start
+}
+
+// graphQLDescription returns the description string or comments above the
+// field at line
+func graphQLDescription(lines []string, line int) string {
+	var text []string
+	for _, l := range lines[graphQLDescriptionStart(lines, line):line] {
+		l = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(l), "#"))
+		l = strings.TrimSpace(strings.ReplaceAll(l, `"""`, ""))
+		if len(l) >= 2 && strings.HasPrefix(l, `"`) && strings.HasSuffix(l, `"`) {
+			l = l[1 : len(l)-1]
+		}
+		text = append(text, l)
+	}
+	return strings.Join(text, " ")
+}
@@ -0,0 +1,298 @@
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Ignore files read from every scanned directory, in increasing precedence
+var ignoreFileNames = []string{".gitignore", ".restgoignore"}
+
+// defaultIgnorePatterns lists paths that never contain hand-written routes.
+// They can be re-included with a negated pattern (e.g. "!vendor/") in an ignore file.
+var defaultIgnorePatterns = []string{
+	".*",
+	"node_modules/",
+	"vendor/",
+	"__pycache__/",
+	"venv/",
+	"dist/",
+	"build/",
+	"target/",
+	"coverage/",
+	"obj/",
+	"*.min.*",
+}
+
+// maxFileSize is the size above which files are not analyzed
+const maxFileSize = 1024 * 1024
+
+// ignoreRule is a single gitignore-style pattern
+type ignoreRule struct {
+	pattern  string // Glob with leading and trailing slashes removed
+	negate   bool   // Pattern started with "!"
+	dirOnly  bool   // Pattern ended with "/"
+	anchored bool   // Pattern contains a slash and is matched relative to base
+	base     string // Slash-separated absolute directory the rule is relative to
+	source   string // Where the rule was declared, for --explain-skip
+}
+
+// ignoreMatcher decides which paths are scanned. It honors built-in defaults,
+// .gitignore and .restgoignore files (nested, with negations) and the
+// --include/--exclude globs.
+type ignoreMatcher struct {
+	root     string
+	files    fileSystem
+	defaults []ignoreRule
+	parents  []ignoreRule            // Rules from ignore files above root, up to the repository root
+	dirRules map[string][]ignoreRule // Rules declared by ignore files in each scanned directory
+	includes []string
+	excludes []string
+}
+
+// newIgnoreMatcher creates a matcher for the tree rooted at root
+func newIgnoreMatcher(files fileSystem, root string, includes, excludes []string) (*ignoreMatcher, error) {
+	for _, glob := range append(append([]string{}, includes...), excludes...) {
+		if _, err := path.Match(strings.ReplaceAll(glob, "**", "*"), ""); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", glob, err)
+		}
+	}
+
+	m := &ignoreMatcher{
+		root:     root,
+		files:    files,
+		dirRules: make(map[string][]ignoreRule),
+		includes: includes,
+		excludes: excludes,
+	}
+
+	rootSlash := filepath.ToSlash(root)
+	for _, pattern := range defaultIgnorePatterns {
+		if rule, ok := parseIgnoreRule(pattern, rootSlash, "built-in default"); ok {
+			m.defaults = append(m.defaults, rule)
+		}
+	}
+
+	// Honor ignore files of enclosing directories when scanning inside a repository
+	if !isRepositoryRoot(files, root) {
+		var ancestors []string
+		for dir := filepath.Dir(root); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+			ancestors = append([]string{dir}, ancestors...)
+			if isRepositoryRoot(files, dir) {
+				for _, ancestor := range ancestors {
+					m.parents = append(m.parents, readIgnoreFiles(files, ancestor)...)
+				}
+				break
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// isRepositoryRoot reports whether dir holds a .git directory or is the
+// root of the tree being read, such as a git revision
+func isRepositoryRoot(files fileSystem, dir string) bool {
+	if _, err := files.Stat(filepath.Join(dir, ".git")); err == nil {
+		return true
+	}
+	return dir == files.Root()
+}
+
+// enterDir loads the ignore files declared in dir. It must be called for
+// each directory before the paths inside it are matched.
+func (m *ignoreMatcher) enterDir(dir string) {
+	m.dirRules[dir] = readIgnoreFiles(m.files, dir)
+}
+
+// skipReason returns why the path should be skipped, or "" if it is scanned
+func (m *ignoreMatcher) skipReason(absPath string, isDir bool, size int64) string {
+	rel, err := filepath.Rel(m.root, absPath)
+	if err != nil || rel == "." {
+		return ""
+	}
+	rel = filepath.ToSlash(rel)
+
+	for _, glob := range m.excludes {
+		if matchUserGlob(glob, rel) {
+			return fmt.Sprintf("matches --exclude %q", glob)
+		}
+	}
+
+	if rule := m.lastMatch(absPath, isDir); rule != nil && !rule.negate {
+		return fmt.Sprintf("ignored by %q (%s)", ruleText(*rule), rule.source)
+	}
+
+	if isDir {
+		return ""
+	}
+
+	if size > maxFileSize {
+		return "larger than 1MB"
+	}
+
+	if len(m.includes) > 0 {
+		for _, glob := range m.includes {
+			if matchUserGlob(glob, rel) {
+				return ""
+			}
+		}
+		return "not matched by any --include glob"
+	}
+
+	return ""
+}
+
+// lastMatch returns the last rule matching the path, following gitignore
+// precedence: defaults, then ignore files from the outermost directory inwards
+func (m *ignoreMatcher) lastMatch(absPath string, isDir bool) *ignoreRule {
+	rules := append([]ignoreRule{}, m.defaults...)
+	rules = append(rules, m.parents...)
+
+	rel, _ := filepath.Rel(m.root, filepath.Dir(absPath))
+	dir := m.root
+	rules = append(rules, m.dirRules[dir]...)
+	if rel != "." {
+		for _, part := range strings.Split(rel, string(filepath.Separator)) {
+			dir = filepath.Join(dir, part)
+			rules = append(rules, m.dirRules[dir]...)
+		}
+	}
+
+	slashPath := filepath.ToSlash(absPath)
+	var match *ignoreRule
+	for i := range rules {
+		if rules[i].matches(slashPath, isDir) {
+			match = &rules[i]
+		}
+	}
+	return match
+}
+
+// matches reports whether the rule applies to the slash-separated absolute path
+func (r ignoreRule) matches(slashPath string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if !strings.HasPrefix(slashPath, r.base+"/") {
+		return false
+	}
+	rel := strings.TrimPrefix(slashPath, r.base+"/")
+
+	if r.anchored {
+		return matchGlob(r.pattern, rel)
+	}
+	return matchGlob(r.pattern, path.Base(rel))
+}
+
+// readIgnoreFiles parses the ignore files present in dir
+func readIgnoreFiles(files fileSystem, dir string) []ignoreRule {
+	var rules []ignoreRule
+	base := filepath.ToSlash(dir)
+
+	for _, name := range ignoreFileNames {
+		content, err := files.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		lineNum := 0
+		for scanner.Scan() {
+			lineNum++
+			source := fmt.Sprintf("%s:%d", filepath.Join(dir, name), lineNum)
+			if rule, ok := parseIgnoreRule(scanner.Text(), base, source); ok {
+				rules = append(rules, rule)
+			}
+		}
+	}
+
+	return rules
+}
+
+// parseIgnoreRule parses one line of a gitignore-style file
+func parseIgnoreRule(line, base, source string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{base: base, source: source}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	rule.pattern = line
+	return rule, true
+}
+
+// ruleText renders a rule back to its gitignore form
+func ruleText(rule ignoreRule) string {
+	text := rule.pattern
+	if rule.anchored && !strings.Contains(text, "/") {
+		text = "/" + text
+	}
+	if rule.dirOnly {
+		text += "/"
+	}
+	if rule.negate {
+		text = "!" + text
+	}
+	return text
+}
+
+// matchUserGlob matches an --include/--exclude glob. Globs without a slash
+// match the base name at any depth; others match the path from the scan root.
+func matchUserGlob(glob, rel string) bool {
+	if !strings.Contains(glob, "/") {
+		return matchGlob(glob, path.Base(rel))
+	}
+	return matchGlob(strings.TrimPrefix(glob, "/"), rel)
+}
+
+// matchGlob matches a slash-separated path against a glob where "**"
+// matches any number of path segments
+func matchGlob(glob, name string) bool {
+	return matchSegments(strings.Split(glob, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(glob, name []string) bool {
+	for len(glob) > 0 {
+		if glob[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(glob[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(glob[0], name[0]); !ok {
+			return false
+		}
+		glob, name = glob[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,159 @@
+package analyzer
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseIgnoreRule(t *testing.T) {
+	tests := []struct {
+		line string
+		ok   bool
+		want ignoreRule
+	}{
+		{line: "", ok: false},
+		{line: "# comment", ok: false},
+		{line: "/", ok: false},
+		{line: "*.log", ok: true, want: ignoreRule{pattern: "*.log"}},
+		{line: "*.log  ", ok: true, want: ignoreRule{pattern: "*.log"}},
+		{line: "!vendor/", ok: true, want: ignoreRule{pattern: "vendor", negate: true, dirOnly: true}},
+		{line: "/build", ok: true, want: ignoreRule{pattern: "build", anchored: true}},
+		{line: "docs/*.md", ok: true, want: ignoreRule{pattern: "docs/*.md", anchored: true}},
+		{line: `\#notes`, ok: true, want: ignoreRule{pattern: "#notes"}},
+		{line: `\!important`, ok: true, want: ignoreRule{pattern: "!important"}},
+	}
+
+	for _, tt := range tests {
+		rule, ok := parseIgnoreRule(tt.line, "/repo", "test")
+		if ok != tt.ok {
+			t.Errorf("parseIgnoreRule(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		tt.want.base, tt.want.source = "/repo", "test"
+		if rule != tt.want {
+			t.Errorf("parseIgnoreRule(%q) = %+v, want %+v", tt.line, rule, tt.want)
+		}
+	}
+}
+
+func TestIgnoreMatcherNegation(t *testing.T) {
+	files := rootedFS{root: "/repo", fsys: fstest.MapFS{
+		".gitignore":        {Data: []byte("*.log\ngenerated/\n!keep.log\n!vendor/\n")},
+		"api/.restgoignore": {Data: []byte("!generated/\nlegacy.go\n")},
+		"api/main.go":       {Data: []byte("package api\n")},
+	}}
+
+	m, err := newIgnoreMatcher(files, "/repo", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.enterDir("/repo")
+	m.enterDir("/repo/api")
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		skipped bool
+	}{
+		{"/repo/app.log", false, true},
+		{"/repo/keep.log", false, false},     // Re-included by a later negation
+		{"/repo/vendor", true, false},        // Built-in default re-included
+		{"/repo/node_modules", true, true},   // Built-in default
+		{"/repo/.env", false, true},          // Hidden files
+		{"/repo/generated", true, true},      // Directory-only rule
+		{"/repo/generated.go", false, false}, // ...which does not apply to files
+		{"/repo/api/generated", true, false}, // Re-included by a nested ignore file
+		{"/repo/api/legacy.go", false, true}, // Rule of a nested ignore file
+		{"/repo/legacy.go", false, false},    // ...which does not apply above it
+		{"/repo/api/main.go", false, false},
+		{"/repo/api/debug.log", false, true}, // Root rules apply in subdirectories
+	}
+
+	for _, tt := range tests {
+		reason := m.skipReason(tt.path, tt.isDir, 0)
+		if (reason != "") != tt.skipped {
+			t.Errorf("skipReason(%s) = %q, want skipped %v", tt.path, reason, tt.skipped)
+		}
+	}
+}
+
+func TestIgnoreMatcherParentRules(t *testing.T) {
+	files := rootedFS{root: "/repo", fsys: fstest.MapFS{
+		".gitignore":              {Data: []byte("*.gen.go\n")},
+		"services/users/users.go": {Data: []byte("package users\n")},
+	}}
+
+	// Ignore files above the scanned directory apply up to the repository root
+	m, err := newIgnoreMatcher(files, "/repo/services/users", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.enterDir("/repo/services/users")
+
+	if reason := m.skipReason("/repo/services/users/api.gen.go", false, 0); reason == "" {
+		t.Error("file ignored by the repository .gitignore was scanned")
+	}
+	if reason := m.skipReason("/repo/services/users/users.go", false, 0); reason != "" {
+		t.Errorf("users.go skipped: %s", reason)
+	}
+}
+
+func TestIgnoreMatcherGlobs(t *testing.T) {
+	files := rootedFS{root: "/repo", fsys: fstest.MapFS{}}
+
+	m, err := newIgnoreMatcher(files, "/repo", []string{"services/**/*.go"}, []string{"**/gen/**"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.enterDir("/repo")
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		skipped bool
+	}{
+		{"/repo/services/users.go", false, false},
+		{"/repo/services/users/api/routes.go", false, false},
+		{"/repo/services/users/app.js", false, true}, // Not included
+		{"/repo/main.go", false, true},
+		{"/repo/services/gen", true, true}, // Excluded
+		{"/repo/services", true, false},    // Includes never prune directories
+	}
+
+	for _, tt := range tests {
+		reason := m.skipReason(tt.path, tt.isDir, 0)
+		if (reason != "") != tt.skipped {
+			t.Errorf("skipReason(%s) = %q, want skipped %v", tt.path, reason, tt.skipped)
+		}
+	}
+
+	if _, err := newIgnoreMatcher(files, "/repo", []string{"[a-"}, nil); err == nil {
+		t.Error("invalid glob was accepted")
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		glob, name string
+		want       bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "cmd/app/main.go", true},
+		{"services/**", "services/users/main.go", true},
+		{"services/**/*.go", "services/main.go", true},
+		{"services/**/*.go", "other/main.go", false},
+		{"**/generated/**", "api/generated/types.go", true},
+		{"**/generated/**", "api/generator.go", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchGlob(tt.glob, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.glob, tt.name, got, tt.want)
+		}
+	}
+}
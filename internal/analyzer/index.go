@@ -0,0 +1,224 @@
+package analyzer
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/tarantino19/restgo/pkg/detector"
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+// indexFormat is bumped whenever the analysis changes in a way the
+// fingerprint of the detectors and patterns does not capture
+const indexFormat = 2
+
+// fileIndex remembers the endpoints detected in each file of a project so
+// that directories whose files did not change are not analyzed again
+type fileIndex struct {
+	Fingerprint string                 `json:"fingerprint"`
+	Dirs        map[string]*indexedDir `json:"dirs"`
+
+	path    string
+	mu      sync.Mutex
+	seen    map[string]bool // Directories analyzed or reused in this run
+	changed bool
+}
+
+// indexedDir holds the files of a directory as they were last analyzed.
+// Directories are the unit of reuse because detectors resolve handlers,
+// constants and middleware across the files of a package.
+type indexedDir struct {
+	Context string                    `json:"context"` // Hash of the manifests and service the directory was analyzed with
+	Files   map[string]*indexedFile   `json:"files"`
+	Types   map[string]*models.Schema `json:"types,omitempty"` // Data types declared by the files
+}
+
+// indexedFile is a file's size, modification time, content hash and endpoints
+type indexedFile struct {
+	Size      int64           `json:"size"`
+	ModTime   int64           `json:"mtime"`
+	Hash      string          `json:"hash"`
+	Endpoints json.RawMessage `json:"endpoints"`
+}
+
+// IndexPath returns where the file index of the project at root is stored
+func IndexPath(root string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(home, ".restapisummarizer", "index", fmt.Sprintf("%x.json", md5.Sum([]byte(root)))), nil
+}
+
+// analysisFingerprint identifies the detectors, patterns and options an
+// index was built with; any change invalidates the whole index
+func analysisFingerprint(detectors []detector.Detector, patterns []FrameworkPatterns, contextTokens int) string {
+	hash := md5.New()
+	fmt.Fprintf(hash, "%d:%d", indexFormat, contextTokens)
+	for _, d := range detectors {
+		fmt.Fprintf(hash, ":%s", d.Name())
+	}
+	if data, err := json.Marshal(patterns); err == nil {
+		hash.Write(data)
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// loadIndex reads the index at path, starting afresh when it is missing,
+// unreadable or was built with another fingerprint
+func loadIndex(path, fingerprint string) *fileIndex {
+	index := &fileIndex{path: path, seen: make(map[string]bool)}
+	if data, err := os.ReadFile(path); err == nil {
+		if json.Unmarshal(data, index) == nil && index.Fingerprint == fingerprint && index.Dirs != nil {
+			return index
+		}
+	}
+
+	index.Fingerprint = fingerprint
+	index.Dirs = make(map[string]*indexedDir)
+	index.changed = true
+	return index
+}
+
+// lookup returns the endpoints and types of a directory when it holds the
+// same files, unchanged, as when it was indexed with the same context
+func (x *fileIndex) lookup(files fileSystem, dir string, paths []string, context string) ([]*models.Endpoint, map[string]*models.Schema, bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.seen[dir] = true
+
+	entry, ok := x.Dirs[dir]
+	if !ok || entry.Context != context || len(entry.Files) != len(paths) {
+		return nil, nil, false
+	}
+
+	for _, path := range paths {
+		file, ok := entry.Files[path]
+		if !ok {
+			return nil, nil, false
+		}
+		info, err := files.Stat(path)
+		if err != nil || info.Size() != file.Size {
+			return nil, nil, false
+		}
+		if info.ModTime().UnixNano() == file.ModTime {
+			continue
+		}
+		// Touched but maybe not modified
+		content, err := files.ReadFile(path)
+		if err != nil || contentHash(content) != file.Hash {
+			return nil, nil, false
+		}
+		file.ModTime = info.ModTime().UnixNano()
+		x.changed = true
+	}
+
+	var endpoints []*models.Endpoint
+	for _, path := range paths {
+		var fileEndpoints []*models.Endpoint
+		if err := json.Unmarshal(entry.Files[path].Endpoints, &fileEndpoints); err != nil {
+			return nil, nil, false
+		}
+		endpoints = append(endpoints, fileEndpoints...)
+	}
+	return endpoints, entry.Types, true
+}
+
+// store records the endpoints and types found in the files of a directory. Endpoints
+// located in files outside the directory, such as Lambda handlers named by
+// a serverless config, would not be invalidated by their changes, so such
+// directories are never reused.
+func (x *fileIndex) store(dir string, files []detector.File, infos map[string]os.FileInfo, context string, endpoints []*models.Endpoint, types map[string]*models.Schema) {
+	byFile := make(map[string][]*models.Endpoint)
+	for _, endpoint := range endpoints {
+		if _, ok := infos[endpoint.File]; !ok {
+			x.forget(dir)
+			return
+		}
+		byFile[endpoint.File] = append(byFile[endpoint.File], endpoint)
+	}
+
+	entry := &indexedDir{Context: context, Files: make(map[string]*indexedFile), Types: types}
+	for _, file := range files {
+		data, err := json.Marshal(byFile[file.Path])
+		if err != nil {
+			x.forget(dir)
+			return
+		}
+		info := infos[file.Path]
+		entry.Files[file.Path] = &indexedFile{
+			Size:      info.Size(),
+			ModTime:   info.ModTime().UnixNano(),
+			Hash:      contentHash(file.Content),
+			Endpoints: data,
+		}
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.Dirs[dir] = entry
+	x.changed = true
+}
+
+// forget drops the entry of a directory
+func (x *fileIndex) forget(dir string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if _, ok := x.Dirs[dir]; ok {
+		delete(x.Dirs, dir)
+		x.changed = true
+	}
+}
+
+// save drops the directories that were not scanned in this run and writes
+// the index if anything changed
+func (x *fileIndex) save() error {
+	for dir := range x.Dirs {
+		if !x.seen[dir] {
+			delete(x.Dirs, dir)
+			x.changed = true
+		}
+	}
+	if !x.changed {
+		return nil
+	}
+
+	data, err := json.Marshal(x)
+	if err != nil {
+		return fmt.Errorf("failed to marshal file index: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(x.path), 0755); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+	if err := os.WriteFile(x.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file index %s: %w", x.path, err)
+	}
+	return nil
+}
+
+// analysisContext hashes what a directory's analysis depends on besides its
+// own files: the dependencies declared by its manifests and its service
+func analysisContext(deps dependencies, service string) string {
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hash := md5.New()
+	fmt.Fprintf(hash, "%s\n", service)
+	for _, name := range names {
+		fmt.Fprintf(hash, "%s=%s\n", name, deps[name])
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// contentHash hashes the content of a file
+func contentHash(content []byte) string {
+	return fmt.Sprintf("%x", md5.Sum(content))
+}
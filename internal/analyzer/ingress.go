@@ -0,0 +1,441 @@
+package analyzer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/tarantino19/restgo/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ingressKindRegex = regexp.MustCompile(`(?m)^kind:\s*Ingress\s*$`)
+	rewriteRefRegex  = regexp.MustCompile(`\$\{?\d+\}?`)
+	proxyPassRegex   = regexp.MustCompile(`^(?:https?|grpcs?)://([^/:$]+)(?::\d+)?(/.*)?$`)
+)
+
+// rewriteTargetAnnotation rewrites the paths matched by an ingress-nginx rule
+const rewriteTargetAnnotation = "nginx.ingress.kubernetes.io/rewrite-target"
+
+// backendSuffixes are stripped from service names before matching a proxy
+// backend such as users-service with the service in services/users
+var backendSuffixes = []string{"-service", "_service", "-svc", "-api", "_api", "-server", "-backend", "-app"}
+
+// proxyRoute maps a public path prefix to the prefix its backend receives
+// after the Ingress or nginx rewrites
+type proxyRoute struct {
+	scheme        string
+	host          string
+	publicPrefix  string
+	backendPrefix string
+	exact         bool   // Only the prefix itself is routed
+	backend       string // Service the requests are forwarded to
+}
+
+// isProxyConfig reports whether path may hold Kubernetes Ingress manifests
+// or nginx configuration
+func isProxyConfig(path string) bool {
+	base := strings.ToLower(filepath.Base(path))
+	switch filepath.Ext(base) {
+	case ".yaml", ".yml", ".conf":
+		return true
+	}
+	return strings.HasPrefix(base, "nginx.conf")
+}
+
+// readProxyRoutes parses the Ingress rules and nginx locations of the
+// given files
+func readProxyRoutes(files fileSystem, paths []string) ([]proxyRoute, []error) {
+	var routes []proxyRoute
+	var errs []error
+
+	for _, path := range paths {
+		content, err := files.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error reading %s: %w", path, err))
+			continue
+		}
+		if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
+			if ingressKindRegex.Match(content) {
+				routes = append(routes, ingressRoutes(content)...)
+			}
+		} else {
+			routes = append(routes, nginxRoutes(content)...)
+		}
+	}
+
+	return routes, errs
+}
+
+// ingressManifest is the part of a networking.k8s.io Ingress read for routing,
+// in both the v1 and the older v1beta1 layout
+type ingressManifest struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Annotations map[string]string `yaml:"annotations"`
+	} `yaml:"metadata"`
+	Spec struct {
+		TLS []struct {
+			Hosts []string `yaml:"hosts"`
+		} `yaml:"tls"`
+		Rules []struct {
+			Host string `yaml:"host"`
+			HTTP struct {
+				Paths []struct {
+					Path     string         `yaml:"path"`
+					PathType string         `yaml:"pathType"`
+					Backend  ingressBackend `yaml:"backend"`
+				} `yaml:"paths"`
+			} `yaml:"http"`
+		} `yaml:"rules"`
+	} `yaml:"spec"`
+}
+
+// ingressBackend names the service of an Ingress path
+type ingressBackend struct {
+	Service struct {
+		Name string `yaml:"name"`
+	} `yaml:"service"`
+	ServiceName string `yaml:"serviceName"`
+}
+
+// ingressRoutes parses every Ingress document of a manifest. Documents
+// that are not valid YAML, such as Helm templates, end the parse.
+func ingressRoutes(content []byte) []proxyRoute {
+	var routes []proxyRoute
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+
+	for {
+		var ingress ingressManifest
+		if err := decoder.Decode(&ingress); err != nil {
+			if !errors.Is(err, io.EOF) {
+				break
+			}
+			return routes
+		}
+		if ingress.Kind != "Ingress" {
+			continue
+		}
+
+		tls := make(map[string]bool)
+		for _, entry := range ingress.Spec.TLS {
+			for _, host := range entry.Hosts {
+				tls[host] = true
+			}
+		}
+		rewrite, rewrites := ingress.Metadata.Annotations[rewriteTargetAnnotation]
+
+		for _, rule := range ingress.Spec.Rules {
+			scheme := "http"
+			if tls[rule.Host] {
+				scheme = "https"
+			}
+			for _, p := range rule.HTTP.Paths {
+				backend := p.Backend.Service.Name
+				if backend == "" {
+					backend = p.Backend.ServiceName
+				}
+				route := proxyRoute{scheme: scheme, host: rule.Host, backend: backend, exact: p.PathType == "Exact"}
+				if p.Path == "" {
+					p.Path = "/"
+				}
+				if rewrites {
+					route.publicPrefix = literalPrefix(p.Path)
+					route.backendPrefix = rewriteRefRegex.ReplaceAllString(rewrite, "")
+				} else {
+					route.publicPrefix, route.backendPrefix = p.Path, p.Path
+				}
+				routes = append(routes, route)
+			}
+		}
+	}
+	return routes
+}
+
+// nginxLocation is a location block of an nginx server
+type nginxLocation struct {
+	server    *nginxServer
+	modifier  string // =, ~, ~* or ^~
+	path      string
+	proxyPass string
+	rewrite   []string // Regex and replacement of the first rewrite
+}
+
+// nginxServer holds the host and scheme of an nginx server block
+type nginxServer struct {
+	name string
+	ssl  bool
+}
+
+// nginxRoutes parses the locations of an nginx configuration that proxy
+// to a backend, resolving upstream blocks to their first server
+func nginxRoutes(content []byte) []proxyRoute {
+	var locations []*nginxLocation
+	upstreams := make(map[string]string)
+
+	var blocks []string // Directives of the enclosing blocks
+	var server *nginxServer
+	var location *nginxLocation
+	var upstream string
+
+	for _, statement := range nginxStatements(string(content)) {
+		fields := strings.Fields(statement)
+		switch {
+		case statement == "}":
+			if len(blocks) == 0 {
+				continue
+			}
+			switch strings.Fields(blocks[len(blocks)-1])[0] {
+			case "server":
+				server = nil
+			case "location":
+				location = nil
+			case "upstream":
+				upstream = ""
+			}
+			blocks = blocks[:len(blocks)-1]
+		case strings.HasSuffix(statement, "{"):
+			fields = strings.Fields(strings.TrimSuffix(statement, "{"))
+			if len(fields) == 0 {
+				fields = []string{"{"}
+			}
+			blocks = append(blocks, strings.Join(fields, " "))
+			switch fields[0] {
+			case "server":
+				server = &nginxServer{}
+			case "upstream":
+				if len(fields) > 1 {
+					upstream = fields[1]
+				}
+			case "location":
+				if server == nil || len(fields) < 2 {
+					continue
+				}
+				location = &nginxLocation{server: server, path: fields[len(fields)-1]}
+				if len(fields) > 2 {
+					location.modifier = fields[1]
+				}
+				locations = append(locations, location)
+			}
+		case len(fields) < 2:
+		case fields[0] == "server" && upstream != "":
+			if _, ok := upstreams[upstream]; !ok {
+				upstreams[upstream] = strings.Split(fields[1], ":")[0]
+			}
+		case fields[0] == "server_name" && server != nil && location == nil:
+			for _, name := range fields[1:] {
+				if name != "_" && server.name == "" {
+					server.name = name
+				}
+			}
+		case fields[0] == "listen" && server != nil:
+			if strings.Contains(statement, " ssl") || strings.HasPrefix(fields[1], "443") || strings.HasSuffix(fields[1], ":443") {
+				server.ssl = true
+			}
+		case fields[0] == "proxy_pass" && location != nil:
+			location.proxyPass = fields[1]
+		case fields[0] == "rewrite" && location != nil && location.rewrite == nil && len(fields) > 2:
+			location.rewrite = fields[1:3]
+		}
+	}
+
+	var routes []proxyRoute
+	for _, location := range locations {
+		matches := proxyPassRegex.FindStringSubmatch(location.proxyPass)
+		if matches == nil {
+			continue
+		}
+		route := proxyRoute{scheme: "http", host: location.server.name, exact: location.modifier == "="}
+		if location.server.ssl {
+			route.scheme = "https"
+		}
+		route.backend = matches[1]
+		if server, ok := upstreams[matches[1]]; ok {
+			route.backend = server
+		}
+
+		route.publicPrefix = location.path
+		if strings.HasPrefix(location.modifier, "~") {
+			route.publicPrefix = literalPrefix(strings.TrimPrefix(location.path, "^"))
+		}
+		route.backendPrefix = route.publicPrefix
+		switch {
+		case location.rewrite != nil:
+			route.publicPrefix = literalPrefix(strings.TrimPrefix(location.rewrite[0], "^"))
+			route.backendPrefix = rewriteRefRegex.ReplaceAllString(location.rewrite[1], "")
+		case matches[2] != "":
+			// A URI on proxy_pass replaces the part matched by the location
+			route.backendPrefix = matches[2]
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// nginxStatements splits an nginx configuration into directives, block
+// openings ending in "{" and block closings "}", dropping comments
+func nginxStatements(content string) []string {
+	var statements []string
+	var current strings.Builder
+	quote := byte(0)
+
+	flush := func(terminator string) {
+		statement := strings.TrimSpace(current.String())
+		current.Reset()
+		if terminator == ";" {
+			if statement != "" {
+				statements = append(statements, statement)
+			}
+			return
+		}
+		if terminator == "{" {
+			statements = append(statements, strings.TrimSpace(statement+" {"))
+			return
+		}
+		if statement != "" {
+			statements = append(statements, statement)
+		}
+		statements = append(statements, "}")
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				current.WriteByte(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+			current.WriteByte(' ')
+		case c == ';' || c == '{' || c == '}':
+			flush(string(c))
+		default:
+			current.WriteByte(c)
+		}
+	}
+	return statements
+}
+
+// literalPrefix returns the leading part of a path regex that contains no
+// regex syntax, such as /api for /api(/|$)(.*)
+func literalPrefix(pattern string) string {
+	if idx := strings.IndexAny(pattern, `()[]{}*+?.\|$^`); idx >= 0 {
+		pattern = pattern[:idx]
+	}
+	return pattern
+}
+
+// assignPublicURLs sets the public URL of every endpoint served behind one
+// of the routes and returns how many endpoints were mapped. An endpoint
+// belongs to the backends named after its service. When all routes lead to
+// the same backend, it also serves the code of a project without services,
+// or with a single one; in a monorepo the other services' endpoints are not
+// behind it.
+func assignPublicURLs(endpoints []*models.Endpoint, routes []proxyRoute) int {
+	backends := make(map[string]bool)
+	for _, route := range routes {
+		backends[backendKey(route.backend)] = true
+	}
+	services := make(map[string]bool)
+	for _, endpoint := range endpoints {
+		if endpoint.Service != "" {
+			services[endpoint.Service] = true
+		}
+	}
+
+	mapped := 0
+	for _, endpoint := range endpoints {
+		if endpoint.Kind == models.KindGRPC || !strings.HasPrefix(endpoint.Path, "/") {
+			continue
+		}
+
+		service := backendKey(endpoint.Service)
+		if !backends[service] && len(backends) == 1 && singleBackendServes(endpoint, services) {
+			for backend := range backends {
+				service = backend
+			}
+		}
+
+		var best *proxyRoute
+		rest := ""
+		for i, route := range routes {
+			if backendKey(route.backend) != service {
+				continue
+			}
+			remainder, ok := trimPathPrefix(endpoint.Path, route.backendPrefix, route.exact)
+			if ok && (best == nil || len(route.backendPrefix) > len(best.backendPrefix)) {
+				best, rest = &routes[i], remainder
+			}
+		}
+		if best == nil {
+			continue
+		}
+
+		public := strings.TrimSuffix(best.publicPrefix, "/") + rest
+		if public == "" {
+			public = "/"
+		}
+		if best.host != "" {
+			public = best.scheme + "://" + best.host + public
+		}
+		endpoint.PublicURL = public
+		mapped++
+	}
+	return mapped
+}
+
+// singleBackendServes reports whether the only backend of the routes may
+// serve an endpoint not named after it: one found in code, in a tree with no
+// services or whose only service is the endpoint's. Serverless functions and
+// imported specs are deployed apart from it.
+func singleBackendServes(endpoint *models.Endpoint, services map[string]bool) bool {
+	if endpoint.Source == models.SourceConfig || endpoint.Source == models.SourceSpec {
+		return false
+	}
+	return len(services) == 0 || (len(services) == 1 && services[endpoint.Service])
+}
+
+// trimPathPrefix removes a path prefix on a segment boundary and returns
+// the rest of the path
+func trimPathPrefix(p, prefix string, exact bool) (string, bool) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	switch {
+	case exact:
+		return "", p == prefix || (prefix == "" && p == "/")
+	case prefix == "":
+		return p, true
+	case p == prefix:
+		return "", true
+	case strings.HasPrefix(p, prefix+"/"):
+		return p[len(prefix):], true
+	}
+	return "", false
+}
+
+// backendKey normalizes a service or backend name for matching: the last
+// path element, without cluster DNS domains and suffixes like -service
+func backendKey(name string) string {
+	name = strings.ToLower(path.Base(name))
+	if idx := strings.Index(name, "."); idx > 0 {
+		name = name[:idx]
+	}
+	for _, suffix := range backendSuffixes {
+		if trimmed := strings.TrimSuffix(name, suffix); trimmed != "" {
+			name = trimmed
+		}
+	}
+	return name
+}
@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+func TestAssignPublicURLs(t *testing.T) {
+	routes := []proxyRoute{{scheme: "https", host: "api.example.com", publicPrefix: "/api", backend: "nest-service"}}
+
+	tests := []struct {
+		name      string
+		endpoints []*models.Endpoint
+		want      []string // Public URL of each endpoint
+	}{
+		{
+			name:      "project without services",
+			endpoints: []*models.Endpoint{{Source: models.SourceCode, Path: "/users"}},
+			want:      []string{"https://api.example.com/api/users"},
+		},
+		{
+			name:      "backend named after the service",
+			endpoints: []*models.Endpoint{{Source: models.SourceCode, Path: "/users", Service: "services/nest"}},
+			want:      []string{"https://api.example.com/api/users"},
+		},
+		{
+			name:      "single service",
+			endpoints: []*models.Endpoint{{Source: models.SourceCode, Path: "/users", Service: "services/users"}},
+			want:      []string{"https://api.example.com/api/users"},
+		},
+		{
+			name: "monorepo",
+			endpoints: []*models.Endpoint{
+				{Source: models.SourceCode, Path: "/users", Service: "services/users"},
+				{Source: models.SourceCode, Path: "/orders", Service: "services/orders"},
+				{Source: models.SourceCode, Path: "/health"},
+			},
+			want: []string{"", "", ""},
+		},
+		{
+			name: "serverless functions and specs",
+			endpoints: []*models.Endpoint{
+				{Source: models.SourceConfig, Path: "/hello"},
+				{Source: models.SourceSpec, Path: "/pets"},
+				{Source: models.SourceCode, Path: "/users"},
+			},
+			want: []string{"", "", "https://api.example.com/api/users"},
+		},
+		{
+			name:      "gRPC methods",
+			endpoints: []*models.Endpoint{{Source: models.SourceCode, Kind: models.KindGRPC, Path: "/users.v1.Users/Get"}},
+			want:      []string{""},
+		},
+	}
+
+	for _, tt := range tests {
+		assignPublicURLs(tt.endpoints, routes)
+		for i, endpoint := range tt.endpoints {
+			if endpoint.PublicURL != tt.want[i] {
+				t.Errorf("%s: %s public URL %q, want %q", tt.name, endpoint.Path, endpoint.PublicURL, tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+// upgradeRegex matches the calls that upgrade an HTTP request to a WebSocket
+// (gorilla/websocket, nhooyr.io/websocket, ASP.NET Core)
+var upgradeRegex = regexp.MustCompile(`\bwebsocket\.Upgrader\b|\b[uU]pgrader\.Upgrade\s*\(|\bwebsocket\.Accept\s*\(|\bAcceptWebSocketAsync\s*\(`)
+
+// detectKind marks routes serving GraphQL and handlers that upgrade the
+// request to a WebSocket; other routes are REST endpoints
+func detectKind(endpoint *models.Endpoint, h *handler) {
+	if endpoint.Kind != "" {
+		return
+	}
+
+	segments := strings.Split(strings.TrimRight(endpoint.Path, "/"), "/")
+	switch {
+	case strings.EqualFold(segments[len(segments)-1], "graphql"):
+		endpoint.Kind = models.KindGraphQL
+	case h != nil && upgradeRegex.MatchString(h.bodyText()):
+		endpoint.Kind = models.KindWebSocket
+	}
+}
@@ -0,0 +1,415 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// dependencies maps a lowercased package name to its declared version
+type dependencies map[string]string
+
+// manifestParsers maps manifest file names to their parsers
+var manifestParsers = map[string]func([]byte) dependencies{
+	"go.mod":           parseGoMod,
+	"package.json":     parsePackageJSON,
+	"requirements.txt": parseRequirements,
+	"pyproject.toml":   parsePyproject,
+	"pom.xml":          parsePomXML,
+	"build.gradle":     parseGradle,
+	"build.gradle.kts": parseGradle,
+	"Gemfile":          parseGemfile,
+}
+
+// serviceMarkers are files whose presence makes a directory a service root
+var serviceMarkers = map[string]bool{
+	"go.mod":           true,
+	"package.json":     true,
+	"pyproject.toml":   true,
+	"pom.xml":          true,
+	"build.gradle":     true,
+	"build.gradle.kts": true,
+	"Dockerfile":       true,
+}
+
+// module describes what is known about a directory from its manifests
+type module struct {
+	deps    dependencies // Dependencies visible from the directory
+	service string       // Service the directory belongs to, "" if none
+}
+
+// moduleResolver finds and caches the dependencies declared by the
+// manifests of a directory and its ancestors, and the service root each
+// directory belongs to
+type moduleResolver struct {
+	root  string
+	files fileSystem
+	mu    sync.Mutex
+	cache map[string]*module
+}
+
+// newModuleResolver creates a resolver that never looks above root
+func newModuleResolver(files fileSystem, root string) *moduleResolver {
+	return &moduleResolver{
+		root:  root,
+		files: files,
+		cache: make(map[string]*module),
+	}
+}
+
+// dependenciesFor returns the merged dependencies visible from dir.
+// Manifests closer to dir take precedence over those further up the tree.
+func (r *moduleResolver) dependenciesFor(dir string) dependencies {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.resolve(dir).deps
+}
+
+// serviceFor returns the service containing dir, named after the path of its
+// root relative to the scan root (or the scan root's name)
+func (r *moduleResolver) serviceFor(dir string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.resolve(dir).service
+}
+
+func (r *moduleResolver) resolve(dir string) *module {
+	if mod, ok := r.cache[dir]; ok {
+		return mod
+	}
+
+	mod := &module{deps: make(dependencies)}
+	parent := filepath.Dir(dir)
+	if dir != r.root && parent != dir && strings.HasPrefix(dir, r.root) {
+		parentMod := r.resolve(parent)
+		for name, version := range parentMod.deps {
+			mod.deps[name] = version
+		}
+		mod.service = parentMod.service
+	}
+
+	deps, isService := readManifests(r.files, dir)
+	for name, version := range deps {
+		mod.deps[name] = version
+	}
+	if isService {
+		mod.service = r.serviceName(dir)
+	}
+
+	r.cache[dir] = mod
+	return mod
+}
+
+// serviceName names the service rooted at dir
+func (r *moduleResolver) serviceName(dir string) string {
+	rel, err := filepath.Rel(r.root, dir)
+	if err != nil || rel == "." {
+		return filepath.Base(r.root)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// readManifests parses every known manifest file in dir and reports
+// whether dir is the root of a service
+func readManifests(files fileSystem, dir string) (dependencies, bool) {
+	deps := make(dependencies)
+	isService := false
+
+	entries, err := files.ReadDir(dir)
+	if err != nil {
+		return deps, false
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if serviceMarkers[entry.Name()] || strings.HasSuffix(entry.Name(), ".csproj") {
+			isService = true
+		}
+
+		parse, ok := manifestParsers[entry.Name()]
+		if !ok && strings.HasSuffix(entry.Name(), ".csproj") {
+			parse, ok = parseCsproj, true
+		}
+		if !ok {
+			continue
+		}
+
+		content, err := files.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		for name, version := range parse(content) {
+			deps[name] = version
+		}
+	}
+
+	return deps, isService
+}
+
+// parseGoMod extracts required modules from a go.mod file
+func parseGoMod(content []byte) dependencies {
+	deps := make(dependencies)
+	inRequire := false
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+
+		switch {
+		case line == "require (":
+			inRequire = true
+			continue
+		case inRequire && line == ")":
+			inRequire = false
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require "))
+		case !inRequire:
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) >= 2 {
+			deps[strings.ToLower(fields[0])] = fields[1]
+		}
+	}
+
+	return deps
+}
+
+// parsePackageJSON extracts dependencies from a package.json file
+func parsePackageJSON(content []byte) dependencies {
+	var pkg struct {
+		Dependencies     map[string]string `json:"dependencies"`
+		DevDependencies  map[string]string `json:"devDependencies"`
+		PeerDependencies map[string]string `json:"peerDependencies"`
+	}
+
+	deps := make(dependencies)
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return deps
+	}
+
+	for _, group := range []map[string]string{pkg.PeerDependencies, pkg.DevDependencies, pkg.Dependencies} {
+		for name, version := range group {
+			deps[strings.ToLower(name)] = version
+		}
+	}
+
+	return deps
+}
+
+// pythonRequirementRegex matches a PEP 508 requirement such as "fastapi[all]>=0.100"
+var pythonRequirementRegex = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._\-]*)\s*(?:\[[^\]]*\])?\s*([=<>!~][^;#\s]*)?`)
+
+// parseRequirements extracts packages from a requirements.txt file
+func parseRequirements(content []byte) dependencies {
+	deps := make(dependencies)
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		addPythonRequirement(deps, line)
+	}
+
+	return deps
+}
+
+// addPythonRequirement parses a single requirement string into deps
+func addPythonRequirement(deps dependencies, requirement string) {
+	matches := pythonRequirementRegex.FindStringSubmatch(requirement)
+	if matches == nil {
+		return
+	}
+	deps[normalizePythonName(matches[1])] = strings.TrimPrefix(matches[2], "==")
+}
+
+// normalizePythonName normalizes a Python distribution name per PEP 503
+func normalizePythonName(name string) string {
+	return strings.NewReplacer("_", "-", ".", "-").Replace(strings.ToLower(name))
+}
+
+var (
+	tomlSectionRegex = regexp.MustCompile(`^\[+([^\]]+)\]+$`)
+	tomlQuotedRegex  = regexp.MustCompile(`["']([^"']+)["']`)
+	poetryDepRegex   = regexp.MustCompile(`^([A-Za-z0-9._\-]+)\s*=\s*(?:["']([^"']*)["']|\{.*version\s*=\s*["']([^"']*)["'].*\})`)
+)
+
+// parsePyproject extracts PEP 621 and Poetry dependencies from a pyproject.toml file
+func parsePyproject(content []byte) dependencies {
+	deps := make(dependencies)
+	section := ""
+	inArray := false
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if matches := tomlSectionRegex.FindStringSubmatch(line); matches != nil {
+			section = matches[1]
+			inArray = false
+			continue
+		}
+
+		// Poetry style: fastapi = "^0.100.0"
+		if strings.HasPrefix(section, "tool.poetry") && strings.HasSuffix(section, "dependencies") {
+			if matches := poetryDepRegex.FindStringSubmatch(line); matches != nil {
+				version := matches[2]
+				if version == "" {
+					version = matches[3]
+				}
+				deps[normalizePythonName(matches[1])] = version
+			}
+			continue
+		}
+
+		// PEP 621 style: dependencies = ["fastapi>=0.100"]
+		if strings.HasPrefix(line, "dependencies") && strings.Contains(line, "[") {
+			inArray = true
+			line = line[strings.Index(line, "[")+1:]
+		}
+		if inArray {
+			for _, matches := range tomlQuotedRegex.FindAllStringSubmatch(line, -1) {
+				addPythonRequirement(deps, matches[1])
+			}
+			if strings.Contains(line, "]") {
+				inArray = false
+			}
+		}
+	}
+
+	return deps
+}
+
+// parsePomXML extracts the parent and dependencies from a Maven pom.xml file
+func parsePomXML(content []byte) dependencies {
+	type artifact struct {
+		GroupID    string `xml:"groupId"`
+		ArtifactID string `xml:"artifactId"`
+		Version    string `xml:"version"`
+	}
+	var pom struct {
+		Parent     artifact `xml:"parent"`
+		Properties struct {
+			Entries []struct {
+				XMLName xml.Name
+				Value   string `xml:",chardata"`
+			} `xml:",any"`
+		} `xml:"properties"`
+		Dependencies []artifact `xml:"dependencies>dependency"`
+	}
+
+	deps := make(dependencies)
+	if err := xml.Unmarshal(content, &pom); err != nil {
+		return deps
+	}
+
+	properties := make(map[string]string)
+	for _, entry := range pom.Properties.Entries {
+		properties[entry.XMLName.Local] = strings.TrimSpace(entry.Value)
+	}
+	properties["project.parent.version"] = pom.Parent.Version
+
+	if pom.Parent.ArtifactID != "" {
+		deps[strings.ToLower(pom.Parent.ArtifactID)] = pom.Parent.Version
+	}
+
+	for _, dep := range pom.Dependencies {
+		version := strings.TrimSpace(dep.Version)
+		if strings.HasPrefix(version, "${") && strings.HasSuffix(version, "}") {
+			version = properties[version[2:len(version)-1]]
+		}
+		// Versions managed by the parent (e.g. spring-boot-starter-parent)
+		if version == "" && dep.GroupID == pom.Parent.GroupID {
+			version = pom.Parent.Version
+		}
+		deps[strings.ToLower(dep.ArtifactID)] = version
+	}
+
+	return deps
+}
+
+var (
+	gradleDepRegex    = regexp.MustCompile(`['"]([\w.\-]+):([\w.\-]+)(?::([\w.\-]+))?['"]`)
+	gradlePluginRegex = regexp.MustCompile(`id\s*\(?\s*['"]([\w.\-]+)['"]\s*\)?\s*version\s*['"]([^'"]+)['"]`)
+)
+
+// parseGradle extracts plugins and dependencies from a Gradle build script
+func parseGradle(content []byte) dependencies {
+	deps := make(dependencies)
+	text := string(content)
+
+	for _, matches := range gradlePluginRegex.FindAllStringSubmatch(text, -1) {
+		deps[strings.ToLower(matches[1])] = matches[2]
+	}
+
+	for _, matches := range gradleDepRegex.FindAllStringSubmatch(text, -1) {
+		version := matches[3]
+		// Versions managed by a plugin of the same group (e.g. org.springframework.boot)
+		if version == "" {
+			version = deps[strings.ToLower(matches[1])]
+		}
+		deps[strings.ToLower(matches[2])] = version
+	}
+
+	return deps
+}
+
+// gemRegex matches gem 'rails', '~> 7.0'
+var gemRegex = regexp.MustCompile(`(?m)^\s*gem\s+['"]([\w\-]+)['"](?:\s*,\s*['"]([^'"]+)['"])?`)
+
+// parseGemfile extracts gems from a Gemfile
+func parseGemfile(content []byte) dependencies {
+	deps := make(dependencies)
+	for _, matches := range gemRegex.FindAllStringSubmatch(string(content), -1) {
+		deps[strings.ToLower(matches[1])] = matches[2]
+	}
+	return deps
+}
+
+// parseCsproj extracts the SDK and package references from a .csproj file
+func parseCsproj(content []byte) dependencies {
+	type reference struct {
+		Include string `xml:"Include,attr"`
+		Version string `xml:"Version,attr"`
+	}
+	var project struct {
+		Sdk                 string      `xml:"Sdk,attr"`
+		TargetFrameworks    []string    `xml:"PropertyGroup>TargetFramework"`
+		PackageReferences   []reference `xml:"ItemGroup>PackageReference"`
+		FrameworkReferences []reference `xml:"ItemGroup>FrameworkReference"`
+	}
+
+	deps := make(dependencies)
+	if err := xml.Unmarshal(content, &project); err != nil {
+		return deps
+	}
+
+	targetFramework := ""
+	if len(project.TargetFrameworks) > 0 {
+		targetFramework = strings.TrimSpace(project.TargetFrameworks[0])
+	}
+
+	if project.Sdk != "" {
+		deps[strings.ToLower(project.Sdk)] = targetFramework
+	}
+	for _, ref := range project.FrameworkReferences {
+		deps[strings.ToLower(ref.Include)] = targetFramework
+	}
+	for _, ref := range project.PackageReferences {
+		deps[strings.ToLower(ref.Include)] = ref.Version
+	}
+
+	return deps
+}
@@ -0,0 +1,433 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+var (
+	// authNameRegex matches, as a whole identifier, the known middleware,
+	// guards, decorators and annotations that enforce authentication
+	authNameRegex = regexp.MustCompile(`(?i)(?:^|\W)(?:` + strings.Join(authNames, "|") + `)(?:$|\W)`)
+	// anonymousRegex matches markers that explicitly allow unauthenticated access
+	anonymousRegex = regexp.MustCompile(`(?i)AllowAnonymous|permitAll|@Public\(`)
+
+	routeCallRegex  = regexp.MustCompile(`(?i)\b(\w+)\.(?:get|post|put|delete|patch|options|head|all|any)\s*\(`)
+	groupDeclRegex  = regexp.MustCompile(`\b(\w+)\s*:?=\s*(\w+)\.Group\s*\(`)
+	useCallRegex    = regexp.MustCompile(`\b(\w+)\.(?:Use|use)\s*\(`)
+	classDeclRegex  = regexp.MustCompile(`^\s*(?:export\s+)?(?:(?:public|private|protected|internal|abstract|sealed|partial|static|final|default)\s+)*class\s+\w+`)
+	controllerRegex = regexp.MustCompile(`@Controller\(\s*(?:['"` + "`" + `]([^'"` + "`" + `]*)['"` + "`" + `]|\{[^}]*path\s*:\s*['"` + "`" + `]([^'"` + "`" + `]*)['"` + "`" + `])?`)
+	apiRouterRegex  = regexp.MustCompile(`^\s*(\w+)\s*=\s*(?:fastapi\.)?(?:APIRouter|FastAPI)\s*\(`)
+	dependsRegex    = regexp.MustCompile(`\b(?:Depends|Security)\(\s*[\w.]+`)
+	decoratorRegex  = regexp.MustCompile(`@([\w.]+)(\((?:"[^"]*"|'[^']*'|[^()"']|\([^()]*\))*\))?`)
+	attributeRegex  = regexp.MustCompile(`\b(Authorize|AllowAnonymous)\b(\([^)]*\))?`)
+)
+
+// authNames are the identifiers of known authentication middleware, guards
+// and annotations; underscores are optional so camelCase spellings match too
+var authNames = []string{
+	`auth`, `authenticated?`, `authorized?`, `authenticate_?token`, `auth_?(?:guard|middleware|required)`,
+	`(?:require|ensure|check|is)_?(?:auth|authenticated|authorized|logged_?in|signed_?in)`,
+	`login_?required`, `protect(?:ed)?`,
+	`jwt`, `jwt_?(?:auth|auth_?guard|guard|middleware|required)`, `verify_?(?:jwt|token|access_?token|api_?key)`, `token_?required`,
+	`bearer_?auth`, `basic_?auth`, `key_?auth`, `api_?key_?(?:auth|guard|required)`, `oauth2?_?scheme`,
+	`roles?`, `roles_?guard`, `(?:require|has|check)_?(?:roles?|permissions?)`, `permissions?_?required`, `roles?_?required`,
+	`is_?admin`, `admin_?(?:only|required)`, `require_?admin`,
+	`pre_?authorize`, `post_?authorize`, `secured`, `roles_?allowed`,
+	`get_?current_?(?:active_?)?user`, `current_?user`,
+}
+
+// annotationMiddleware lists, per decorator framework, the annotations that
+// guard an endpoint. Flask treats every extra decorator as middleware.
+var annotationMiddleware = map[string]map[string]bool{
+	"Spring": {"PreAuthorize": true, "PostAuthorize": true, "Secured": true, "RolesAllowed": true, "PermitAll": true, "DenyAll": true},
+	"NestJS": {"UseGuards": true, "Roles": true, "Public": true, "UseInterceptors": true},
+}
+
+// routeScope is a router, route group or Express router that routes are
+// registered on, along with the middleware it applies
+type routeScope struct {
+	parent string
+	prefix string
+	uses   []scopedMiddleware
+}
+
+// scopedMiddleware is middleware registered with Use, optionally only for a path prefix
+type scopedMiddleware struct {
+	prefix string
+	name   string
+}
+
+// detectMiddleware records the middleware, guards and auth annotations that
+// apply to an endpoint and whether they require authentication
+func detectMiddleware(endpoint *models.Endpoint, h *handler, src *sourceFile, pkg *sourcePackage) {
+	var middleware []string
+	line := endpoint.Line - 1
+
+	switch endpoint.Framework {
+	case "Express", "Gin", "Echo":
+		middleware = routeMiddleware(endpoint.Path, src, pkg, line)
+	case "Spring", "NestJS", "Flask":
+		middleware = decoratorMiddleware(endpoint.Framework, src, line)
+	case "ASP.NET":
+		middleware = attributeMiddleware(src, line)
+	case "FastAPI":
+		middleware = fastAPIMiddleware(src, h, line)
+	}
+
+	endpoint.Middleware = middleware
+	endpoint.AuthRequired = requiresAuth(middleware)
+}
+
+// requiresAuth reports whether the middleware enforce authentication and no
+// marker explicitly allows anonymous access
+func requiresAuth(middleware []string) bool {
+	auth := false
+	for _, name := range middleware {
+		if anonymousRegex.MatchString(name) {
+			return false
+		}
+		if authNameRegex.MatchString(name) {
+			auth = true
+		}
+	}
+	return auth
+}
+
+// routeMiddleware collects the middleware of a route registered with a call
+// such as app.get("/path", auth, handler), including the middleware added with
+// Use on its router and, for Go, on the enclosing router groups
+func routeMiddleware(path string, src *sourceFile, pkg *sourcePackage, line int) []string {
+	text, loc := routeCall(src, line)
+	if loc == nil {
+		return nil
+	}
+	receiver := text[loc[2]:loc[3]]
+
+	scopes := routeScopes(src, pkg, line)
+	var middleware []string
+	visited := make(map[string]bool)
+	for name := receiver; name != "" && !visited[name]; {
+		visited[name] = true
+		scope, ok := scopes[name]
+		if !ok {
+			break
+		}
+		var uses []string
+		for _, use := range scope.uses {
+			if use.prefix == "" || strings.HasPrefix(path, use.prefix) {
+				uses = append(uses, use.name)
+			}
+		}
+		// Outer scopes run first
+		middleware = append(uses, middleware...)
+		name = scope.parent
+	}
+
+	args := callArguments(text, loc[1]-1)
+	if len(args) > 1 {
+		handlerIdx := len(args) - 1
+		for i := 1; i < len(args); i++ {
+			if inlineFuncRegex.MatchString(args[i]) {
+				handlerIdx = i
+				break
+			}
+		}
+		middleware = append(middleware, args[1:handlerIdx]...)
+	}
+
+	return uniqueStrings(middleware)
+}
+
+// routeCall returns the text from line on and the submatch indexes of the
+// route call registering the endpoint there, or nil
+func routeCall(src *sourceFile, line int) (string, []int) {
+	if line < 0 || line >= len(src.lines) {
+		return "", nil
+	}
+	text := strings.Join(src.lines[line:min(line+30, len(src.lines))], "\n")
+	return text, routeCallRegex.FindStringSubmatchIndex(text)
+}
+
+// routeScopes parses the router groups and Use calls declared in src before
+// line, resolving group prefixes built from constants
+func routeScopes(src *sourceFile, pkg *sourcePackage, line int) map[string]*routeScope {
+	scopes := make(map[string]*routeScope)
+	scope := func(name string) *routeScope {
+		if scopes[name] == nil {
+			scopes[name] = &routeScope{}
+		}
+		return scopes[name]
+	}
+
+	for i := 0; i < line; i++ {
+		text := src.lines[i]
+		if isCommentLine(strings.TrimSpace(text)) {
+			continue
+		}
+		rest := strings.Join(src.lines[i:min(i+10, len(src.lines))], "\n")
+
+		if loc := groupDeclRegex.FindStringSubmatchIndex(rest); loc != nil && loc[0] < len(text) {
+			args := callArguments(rest, loc[1]-1)
+			group := &routeScope{parent: rest[loc[4]:loc[5]]}
+			if len(args) > 0 {
+				group.prefix = pkg.resolveRoutePath(src.path, args[0], true)
+				for _, arg := range args[1:] {
+					group.uses = append(group.uses, scopedMiddleware{name: arg})
+				}
+			}
+			scopes[rest[loc[2]:loc[3]]] = group
+			continue
+		}
+
+		if loc := useCallRegex.FindStringSubmatchIndex(rest); loc != nil && loc[0] < len(text) {
+			args := callArguments(rest, loc[1]-1)
+			prefix := ""
+			if len(args) > 0 && isStringLiteral(args[0]) {
+				prefix, args = unquote(args[0]), args[1:]
+			}
+			target := scope(rest[loc[2]:loc[3]])
+			for _, arg := range args {
+				target.uses = append(target.uses, scopedMiddleware{prefix: prefix, name: arg})
+			}
+		}
+	}
+
+	return scopes
+}
+
+// decoratorMiddleware collects the guard annotations and decorators on a
+// handler and its class
+func decoratorMiddleware(framework string, src *sourceFile, line int) []string {
+	var blocks []string
+	if classLine := enclosingClass(src, line); classLine >= 0 && framework != "Flask" {
+		blocks = append(blocks, classAnnotations(src.lines, classLine))
+	}
+	blocks = append(blocks, annotationBlock(src.lines, line))
+
+	var middleware []string
+	for _, block := range blocks {
+		for _, matches := range decoratorRegex.FindAllStringSubmatch(block, -1) {
+			name := matches[1]
+			if framework == "Flask" {
+				// Skip route decorators, which register rather than guard the endpoint
+				if strings.Contains(name, ".") && (strings.HasSuffix(name, ".route") || routeCallRegex.MatchString(name+"(")) {
+					continue
+				}
+			} else if !annotationMiddleware[framework][name] {
+				continue
+			}
+			middleware = append(middleware, collapseSpace(matches[0]))
+		}
+	}
+
+	return uniqueStrings(middleware)
+}
+
+// attributeMiddleware collects the [Authorize] and [AllowAnonymous]
+// attributes on an ASP.NET action and its controller
+func attributeMiddleware(src *sourceFile, line int) []string {
+	var blocks []string
+	if classLine := enclosingClass(src, line); classLine >= 0 {
+		blocks = append(blocks, classAnnotations(src.lines, classLine))
+	}
+	blocks = append(blocks, annotationBlock(src.lines, line))
+
+	var middleware []string
+	for _, block := range blocks {
+		for _, matches := range attributeRegex.FindAllStringSubmatch(block, -1) {
+			middleware = append(middleware, "["+collapseSpace(matches[0])+"]")
+		}
+	}
+	return uniqueStrings(middleware)
+}
+
+// fastAPIMiddleware collects the Depends() and Security() dependencies of a
+// FastAPI route: those of its router, its decorator and its signature
+func fastAPIMiddleware(src *sourceFile, h *handler, line int) []string {
+	var middleware []string
+
+	block := annotationBlock(src.lines, line)
+	if matches := routeCallRegex.FindStringSubmatch(block); matches != nil {
+		for i, text := range src.lines {
+			router := apiRouterRegex.FindStringSubmatch(text)
+			if router == nil || router[1] != matches[1] {
+				continue
+			}
+			rest := strings.Join(src.lines[i:min(i+10, len(src.lines))], "\n")
+			for _, arg := range callArguments(rest, strings.Index(rest, "(")) {
+				if strings.HasPrefix(arg, "dependencies") {
+					middleware = append(middleware, dependencyCalls(arg)...)
+				}
+			}
+		}
+	}
+
+	middleware = append(middleware, dependencyCalls(block)...)
+	if h != nil {
+		middleware = append(middleware, dependencyCalls(h.signature)...)
+	}
+	return uniqueStrings(middleware)
+}
+
+// dependencyCalls returns the Depends() and Security() calls in text
+func dependencyCalls(text string) []string {
+	var calls []string
+	for _, match := range dependsRegex.FindAllString(text, -1) {
+		calls = append(calls, collapseSpace(match)+")")
+	}
+	return calls
+}
+
+// enclosingClass returns the line of the class declared before line, or -1
+func enclosingClass(src *sourceFile, line int) int {
+	for i := min(line, len(src.lines)-1); i >= 0; i-- {
+		if classDeclRegex.MatchString(src.lines[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// annotationBlock returns the decorators, annotations or attributes
+// surrounding line, following annotations that span several lines
+func annotationBlock(lines []string, line int) string {
+	if line < 0 || line >= len(lines) {
+		return ""
+	}
+	block := annotationsAbove(lines, line)
+
+	// Annotations below, up to the declaration
+	depth := strings.Count(lines[line], "(") - strings.Count(lines[line], ")")
+	for i := line + 1; i < len(lines) && i <= line+30; i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if depth <= 0 && trimmed != "" && !isCommentLine(trimmed) && !annotationLnRegex.MatchString(lines[i]) {
+			break
+		}
+		depth += strings.Count(lines[i], "(") - strings.Count(lines[i], ")")
+		block = append(block, lines[i])
+	}
+
+	return strings.Join(block, "\n")
+}
+
+// classAnnotations returns the annotations or attributes of the class
+// declared at line. Unlike annotationBlock it stops at the declaration, as
+// the annotations below it belong to the first member.
+func classAnnotations(lines []string, line int) string {
+	if line < 0 || line >= len(lines) {
+		return ""
+	}
+	return strings.Join(annotationsAbove(lines, line), "\n")
+}
+
+// annotationsAbove returns line preceded by the annotations above it,
+// including the tail of multi-line ones
+func annotationsAbove(lines []string, line int) []string {
+	block := []string{lines[line]}
+	depth := 0
+	for i := line - 1; i >= 0 && i >= line-30; i-- {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || isCommentLine(trimmed) {
+			continue
+		}
+		closes := strings.Count(trimmed, ")") - strings.Count(trimmed, "(")
+		if depth <= 0 && closes <= 0 && !annotationLnRegex.MatchString(lines[i]) {
+			break
+		}
+		depth = max(depth+closes, 0)
+		block = append([]string{lines[i]}, block...)
+	}
+	return block
+}
+
+// callArguments splits the arguments of the call whose opening parenthesis
+// is at open, honoring nested brackets and string literals
+func callArguments(text string, open int) []string {
+	if open < 0 || open >= len(text) || text[open] != '(' {
+		return nil
+	}
+
+	var args []string
+	depth := 0
+	start := open + 1
+	var quote byte
+
+	for i := open; i < len(text); i++ {
+		c := text[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch c {
+		case '"', '\'', '`':
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth == 0 {
+				if arg := collapseSpace(text[start:i]); arg != "" {
+					args = append(args, arg)
+				}
+				return args
+			}
+		case ',':
+			if depth == 1 {
+				args = append(args, collapseSpace(text[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	return args
+}
+
+// joinRoutePath joins a route prefix and path
+func joinRoutePath(prefix, path string) string {
+	if prefix == "" {
+		return path
+	}
+	if path == "" || path == "/" {
+		return "/" + strings.Trim(prefix, "/")
+	}
+	return "/" + strings.Trim(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// isStringLiteral reports whether an argument is a quoted string
+func isStringLiteral(arg string) bool {
+	return len(arg) >= 2 && strings.ContainsRune(`"'`+"`", rune(arg[0])) && arg[len(arg)-1] == arg[0]
+}
+
+// unquote removes the quotes around a string literal
+func unquote(arg string) string {
+	if isStringLiteral(arg) {
+		return arg[1 : len(arg)-1]
+	}
+	return arg
+}
+
+// collapseSpace trims s and replaces runs of whitespace with a single space
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// uniqueStrings removes duplicates, keeping the first occurrence
+func uniqueStrings(values []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,110 @@
+package analyzer
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+// analyzeTree analyzes an in-memory tree rooted at /repo and returns its
+// endpoints keyed by "METHOD path"
+func analyzeTree(t *testing.T, files map[string]string) map[string]*models.Endpoint {
+	t.Helper()
+	fsys := fstest.MapFS{}
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+
+	endpoints, err := NewAnalyzer(Options{Jobs: 1, FS: fsys, FSRoot: "/repo"}).AnalyzeDirectory("/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byRoute := make(map[string]*models.Endpoint)
+	for _, endpoint := range endpoints {
+		byRoute[endpoint.Method+" "+endpoint.Path] = endpoint
+	}
+	return byRoute
+}
+
+func TestClassGuardsStopAtDeclaration(t *testing.T) {
+	endpoints := analyzeTree(t, map[string]string{
+		"nest/package.json": `{"dependencies": {"@nestjs/core": "^10.0.0"}}`,
+		"nest/src/users.controller.ts": `import { Controller, Get, Post, UseGuards } from '@nestjs/common';
+
+@Controller('users')
+export class UsersController {
+  @UseGuards(AuthGuard('jwt'))
+  @Get()
+  findAll() {
+    return [];
+  }
+
+  @Post()
+  create() {
+    return {};
+  }
+}
+`,
+		"spring/pom.xml": `<project><dependencies><dependency><artifactId>spring-boot-starter-web</artifactId></dependency></dependencies></project>`,
+		"spring/src/main/java/OrderController.java": `package com.example;
+
+@RestController
+public class OrderController {
+    @PreAuthorize("hasRole('ADMIN')")
+    @GetMapping("/orders/recent")
+    public List<Order> list() {
+        return List.of();
+    }
+
+    @PostMapping("/orders/draft")
+    public Order create(@RequestBody Order order) {
+        return order;
+    }
+}
+`,
+	})
+
+	tests := []struct {
+		route      string
+		middleware int
+		auth       bool
+	}{
+		{"GET /users", 1, true},
+		{"POST /users", 0, false},
+		{"GET /orders/recent", 1, true},
+		{"POST /orders/draft", 0, false},
+	}
+
+	for _, tt := range tests {
+		endpoint := endpoints[tt.route]
+		if endpoint == nil {
+			t.Errorf("%s not detected", tt.route)
+			continue
+		}
+		if len(endpoint.Middleware) != tt.middleware || endpoint.AuthRequired != tt.auth {
+			t.Errorf("%s: middleware %v, auth %v; want %d middleware, auth %v",
+				tt.route, endpoint.Middleware, endpoint.AuthRequired, tt.middleware, tt.auth)
+		}
+	}
+}
+
+func TestClassAnnotations(t *testing.T) {
+	lines := []string{
+		"@Authorize(Roles = \"Admin\",",
+		"    Policy = \"Staff\")",
+		"[ApiController]",
+		"public class UsersController {",
+		"    @PreAuthorize(\"hasRole('USER')\")",
+		"    public void list() {}",
+		"}",
+	}
+
+	want := "@Authorize(Roles = \"Admin\",\n    Policy = \"Staff\")\n[ApiController]\npublic class UsersController {"
+	if got := classAnnotations(lines, 3); got != want {
+		t.Errorf("classAnnotations = %q, want %q", got, want)
+	}
+	if got := classAnnotations(lines, len(lines)); got != "" {
+		t.Errorf("classAnnotations past the end = %q", got)
+	}
+}
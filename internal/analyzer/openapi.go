@@ -0,0 +1,472 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tarantino19/restgo/pkg/detector"
+	"github.com/tarantino19/restgo/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// specMarkerRegex matches the version key of an OpenAPI or Swagger document,
+// on its own line or inside minified JSON
+var specMarkerRegex = regexp.MustCompile(`(?m)(?:^|[\s{,])["']?(openapi|swagger)["']?\s*:\s*["']?(\d+(?:\.\d+)*)`)
+
+// specMethods are the operation keys of a path item, in output order
+var specMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// specHeadSize is how much of a YAML or JSON file is searched for the
+// version key of a spec
+const specHeadSize = 4096
+
+// OpenAPIDetector imports the endpoints declared in OpenAPI 3.x and
+// Swagger 2.0 documents
+type OpenAPIDetector struct {
+	files fileSystem // Where candidate documents are sniffed
+}
+
+// NewOpenAPIDetector creates a detector for OpenAPI and Swagger files
+func NewOpenAPIDetector() *OpenAPIDetector {
+	return newOpenAPIDetector(osFileSystem{})
+}
+
+// newOpenAPIDetector creates a detector sniffing documents in files
+func newOpenAPIDetector(files fileSystem) *OpenAPIDetector {
+	return &OpenAPIDetector{files: files}
+}
+
+// Name implements detector.Detector
+func (d *OpenAPIDetector) Name() string {
+	return "openapi"
+}
+
+// Supports reports whether path is a YAML or JSON file declaring an
+// OpenAPI or Swagger version near its top, so that other configuration
+// files such as package-lock.json or CI workflows are not read in full
+func (d *OpenAPIDetector) Supports(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return false
+	}
+	head, err := d.files.ReadHead(path, specHeadSize)
+	return err == nil && specMarkerRegex.Match(head)
+}
+
+// Detect returns the operations of the OpenAPI documents among files,
+// ignoring other YAML and JSON files
+func (d *OpenAPIDetector) Detect(files []detector.File) ([]*models.Endpoint, error) {
+	var endpoints []*models.Endpoint
+	var errs []string
+
+	for _, file := range files {
+		head := file.Content[:min(len(file.Content), specHeadSize)]
+		if !specMarkerRegex.Match(head) {
+			continue
+		}
+		found, err := specOperations(file)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", file.Path, err))
+			continue
+		}
+		endpoints = append(endpoints, found...)
+	}
+
+	if len(errs) > 0 {
+		return endpoints, fmt.Errorf("invalid OpenAPI documents: %s", strings.Join(errs, "; "))
+	}
+	return endpoints, nil
+}
+
+// openAPISpec is a parsed OpenAPI document
+type openAPISpec struct {
+	root  map[string]any
+	lines map[string]int // Line of each operation, keyed by "path method"
+}
+
+// specOperations parses an OpenAPI document into endpoints
+func specOperations(file detector.File) ([]*models.Endpoint, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(file.Content, &node); err != nil {
+		return nil, fmt.Errorf("error parsing document: %w", err)
+	}
+	var decoded any
+	if err := node.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("error decoding document: %w", err)
+	}
+	root := mapValue(stringKeys(decoded))
+	spec := &openAPISpec{root: root, lines: operationLines(&node)}
+
+	version := stringValue(root["openapi"])
+	if version == "" {
+		version = stringValue(root["swagger"])
+	}
+	basePath := strings.TrimRight(specBasePath(root), "/")
+	paths := mapValue(root["paths"])
+
+	var routes []string
+	for route := range paths {
+		routes = append(routes, route)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		return spec.lines[routes[i]] < spec.lines[routes[j]]
+	})
+
+	var endpoints []*models.Endpoint
+	for _, route := range routes {
+		item := mapValue(spec.resolve(paths[route]))
+		for _, method := range specMethods {
+			op := mapValue(item[method])
+			if op == nil {
+				continue
+			}
+
+			endpoint := &models.Endpoint{
+				Kind:             models.KindREST,
+				Source:           models.SourceSpec,
+				Method:           strings.ToUpper(method),
+				Path:             basePath + route,
+				File:             file.Path,
+				Line:             spec.lines[route+" "+method],
+				Function:         stringValue(op["operationId"]),
+				Description:      stringValue(op["description"]),
+				Language:         "OpenAPI",
+				Framework:        "OpenAPI",
+				FrameworkVersion: version,
+				Version:          pathVersion(basePath + route),
+			}
+			if raw, err := yaml.Marshal(map[string]any{route: map[string]any{method: op}}); err == nil {
+				endpoint.RawCode = strings.TrimSpace(string(raw))
+			}
+
+			summary := stringValue(op["summary"])
+			if summary == "" {
+				summary = firstSentence(endpoint.Description, "")
+			}
+			if summary != "" {
+				endpoint.Summary = summary
+				endpoint.SummarySource = models.SummaryFromDocs
+			}
+			for _, tag := range listValue(op["tags"]) {
+				addTag(endpoint, stringValue(tag))
+			}
+
+			// Operation parameters override those of the path item
+			for _, params := range [][]any{listValue(item["parameters"]), listValue(op["parameters"])} {
+				for _, param := range params {
+					spec.addParameter(endpoint, mapValue(spec.resolve(param)))
+				}
+			}
+			if body := mapValue(spec.resolve(op["requestBody"])); body != nil {
+				endpoint.Request = spec.mediaSchema(body)
+			}
+			spec.addResponses(endpoint, mapValue(op["responses"]))
+
+			security, ok := op["security"]
+			if !ok {
+				security = root["security"]
+			}
+			for _, requirement := range listValue(security) {
+				for name := range mapValue(requirement) {
+					endpoint.Middleware = uniqueStrings(append(endpoint.Middleware, "@Security "+name))
+				}
+			}
+			endpoint.AuthRequired = len(endpoint.Middleware) > 0
+
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+
+	return endpoints, nil
+}
+
+// addParameter records a parameter; Swagger 2.0 body parameters set the
+// request schema instead
+func (s *openAPISpec) addParameter(endpoint *models.Endpoint, param map[string]any) {
+	in := stringValue(param["in"])
+	switch in {
+	case "body":
+		endpoint.Request = s.schema(param["schema"])
+		return
+	case models.ParamPath, models.ParamQuery, models.ParamHeader, models.ParamCookie:
+	default:
+		return
+	}
+
+	// OpenAPI 3 nests the type in a schema; Swagger 2.0 declares it inline
+	schema := mapValue(s.resolve(param["schema"]))
+	if schema == nil {
+		schema = param
+	}
+	parsed := models.Parameter{
+		Name:        stringValue(param["name"]),
+		In:          in,
+		Type:        s.typeName(schema),
+		Required:    param["required"] == true || in == models.ParamPath,
+		Description: stringValue(param["description"]),
+	}
+	if def, ok := schema["default"]; ok {
+		parsed.Default = fmt.Sprint(def)
+	}
+
+	for i, existing := range endpoint.Parameters {
+		if existing.In == parsed.In && existing.Name == parsed.Name {
+			endpoint.Parameters[i] = parsed
+			return
+		}
+	}
+	endpoint.Parameters = append(endpoint.Parameters, parsed)
+}
+
+// addResponses records the documented responses; the first success
+// response with a body becomes the response schema
+func (s *openAPISpec) addResponses(endpoint *models.Endpoint, responses map[string]any) {
+	var codes []int
+	for code := range responses {
+		// "default" and ranges such as "2XX" have no single status
+		if status, err := strconv.Atoi(code); err == nil {
+			codes = append(codes, status)
+		}
+	}
+	sort.Ints(codes)
+
+	for _, status := range codes {
+		response := mapValue(s.resolve(responses[strconv.Itoa(status)]))
+		schema := s.mediaSchema(response)
+		if schema == nil && response["schema"] != nil {
+			// Swagger 2.0 declares the body schema directly
+			schema = s.schema(response["schema"])
+		}
+
+		name := ""
+		if schema != nil {
+			name = schema.Name
+			if endpoint.Response == nil && status >= 200 && status < 300 {
+				endpoint.Response = schema
+			}
+		}
+		endpoint.Responses = append(endpoint.Responses, models.Response{
+			Status:      status,
+			Description: stringValue(response["description"]),
+			Schema:      name,
+		})
+		endpoint.StatusCodes = append(endpoint.StatusCodes, status)
+	}
+}
+
+// mediaSchema returns the schema of the first media type of an OpenAPI 3
+// request body or response, preferring JSON
+func (s *openAPISpec) mediaSchema(value map[string]any) *models.Schema {
+	content := mapValue(value["content"])
+	if len(content) == 0 {
+		return nil
+	}
+
+	var types []string
+	for mediaType := range content {
+		types = append(types, mediaType)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		iJSON, jJSON := strings.Contains(types[i], "json"), strings.Contains(types[j], "json")
+		if iJSON != jJSON {
+			return iJSON
+		}
+		return types[i] < types[j]
+	})
+
+	media := mapValue(content[types[0]])
+	if media["schema"] == nil {
+		return nil
+	}
+	return s.schema(media["schema"])
+}
+
+// schema converts a JSON schema into a named schema with its top-level
+// properties
+func (s *openAPISpec) schema(value any) *models.Schema {
+	resolved := mapValue(s.resolve(value))
+	if resolved == nil {
+		return nil
+	}
+	schema := &models.Schema{Name: s.typeName(mapValue(value))}
+
+	properties := resolved
+	if stringValue(resolved["type"]) == "array" {
+		properties = mapValue(s.resolve(resolved["items"]))
+	}
+	required := make(map[string]bool)
+	for _, name := range listValue(properties["required"]) {
+		required[stringValue(name)] = true
+	}
+
+	props := mapValue(properties["properties"])
+	var names []string
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		schema.Fields = append(schema.Fields, models.Field{
+			Name:     name,
+			Type:     s.typeName(mapValue(props[name])),
+			Required: required[name],
+		})
+	}
+	return schema
+}
+
+// typeName describes a schema by its $ref name, or by its type and format
+func (s *openAPISpec) typeName(schema map[string]any) string {
+	if ref := stringValue(schema["$ref"]); ref != "" {
+		return ref[strings.LastIndex(ref, "/")+1:]
+	}
+	switch typ := stringValue(schema["type"]); typ {
+	case "array":
+		return "[]" + s.typeName(mapValue(schema["items"]))
+	case "":
+		return "object"
+	default:
+		if format := stringValue(schema["format"]); format != "" {
+			return typ + "(" + format + ")"
+		}
+		return typ
+	}
+}
+
+// resolve follows local $ref pointers such as #/components/schemas/User.
+// References to other files are left unresolved.
+func (s *openAPISpec) resolve(value any) any {
+	seen := make(map[string]bool)
+	for {
+		ref := stringValue(mapValue(value)["$ref"])
+		if !strings.HasPrefix(ref, "#/") || seen[ref] {
+			return value
+		}
+		seen[ref] = true
+
+		var target any = s.root
+		for _, token := range strings.Split(ref[2:], "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+			if unescaped, err := url.PathUnescape(token); err == nil {
+				token = unescaped
+			}
+			target = mapValue(target)[token]
+		}
+		if target == nil {
+			return value
+		}
+		value = target
+	}
+}
+
+// specBasePath returns the path prefix of the API: the Swagger 2.0
+// basePath or the path of the first OpenAPI 3 server URL
+func specBasePath(root map[string]any) string {
+	if basePath := stringValue(root["basePath"]); basePath != "" {
+		return basePath
+	}
+	servers := listValue(root["servers"])
+	if len(servers) == 0 {
+		return ""
+	}
+	raw := stringValue(mapValue(servers[0])["url"])
+	if strings.Contains(raw, "{") {
+		return ""
+	}
+	if parsed, err := url.Parse(raw); err == nil {
+		return parsed.Path
+	}
+	return ""
+}
+
+// operationLines maps each path, and each "path method" operation, to its
+// line in the document
+func operationLines(root *yaml.Node) map[string]int {
+	lines := make(map[string]int)
+	doc := root
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+
+	paths := mappingValue(doc, "paths")
+	if paths == nil {
+		return lines
+	}
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		route := paths.Content[i].Value
+		lines[route] = paths.Content[i].Line
+		item := paths.Content[i+1]
+		for j := 0; j+1 < len(item.Content); j += 2 {
+			lines[route+" "+item.Content[j].Value] = item.Content[j].Line
+		}
+	}
+	return lines
+}
+
+// mappingValue returns the value of key in a YAML mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// stringKeys converts the maps of a decoded YAML value to string keys, as
+// unquoted keys such as response codes decode as numbers
+func stringKeys(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = stringKeys(item)
+		}
+		return v
+	case map[any]any:
+		converted := make(map[string]any, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = stringKeys(item)
+		}
+		return converted
+	case []any:
+		for i, item := range v {
+			v[i] = stringKeys(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// mapValue returns value as a map, or nil
+func mapValue(value any) map[string]any {
+	m, _ := value.(map[string]any)
+	return m
+}
+
+// listValue returns value as a list, or nil
+func listValue(value any) []any {
+	l, _ := value.([]any)
+	return l
+}
+
+// stringValue returns value as a string, or ""
+func stringValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,278 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/tarantino19/restgo/pkg/detector"
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+const swaggerSpec = `swagger: "2.0"
+basePath: /api/v1
+paths:
+  /users:
+    post:
+      operationId: createUser
+      summary: Create a user
+      parameters:
+        - name: X-Request-ID
+          in: header
+          type: string
+        - name: user
+          in: body
+          required: true
+          schema:
+            $ref: "#/definitions/User"
+      responses:
+        "201":
+          description: Created
+          schema:
+            $ref: "#/definitions/User"
+        "400":
+          description: Invalid user
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        type: integer
+        format: int64
+    get:
+      description: Returns a user. Deleted users are not found.
+      responses:
+        "200":
+          description: OK
+          schema:
+            $ref: "#/definitions/Missing"
+definitions:
+  User:
+    type: object
+    required: [email]
+    properties:
+      email:
+        type: string
+      name:
+        type: string
+`
+
+const openAPI3Spec = `openapi: 3.0.3
+servers:
+  - url: https://api.example.com/v2
+security:
+  - bearerAuth: []
+paths:
+  /orders:
+    get:
+      tags: [orders]
+      parameters:
+        - $ref: "#/components/parameters/Page"
+      security: []
+      responses:
+        "200":
+          description: Orders
+          content:
+            text/plain:
+              schema:
+                type: string
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Order"
+    post:
+      requestBody:
+        $ref: "#/components/requestBodies/NewOrder"
+      responses:
+        "201":
+          description: Created
+        default:
+          description: Error
+components:
+  parameters:
+    Page:
+      name: page
+      in: query
+      schema:
+        type: integer
+        default: 1
+  requestBodies:
+    NewOrder:
+      content:
+        application/json:
+          schema:
+            $ref: "#/components/schemas/Order"
+  schemas:
+    Order:
+      type: object
+      properties:
+        id:
+          type: string
+          format: uuid
+        total:
+          type: number
+`
+
+func TestOpenAPIDetectorSwagger2(t *testing.T) {
+	endpoints, err := NewOpenAPIDetector().Detect([]detector.File{
+		{Path: "/repo/swagger.yaml", Content: []byte(swaggerSpec)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoints) != 2 {
+		t.Fatalf("got %d endpoints, want 2", len(endpoints))
+	}
+
+	create := endpoints[0]
+	if create.Method != "POST" || create.Path != "/api/v1/users" || create.Line != 5 {
+		t.Errorf("create = %s %s (line %d), want POST /api/v1/users (line 5)", create.Method, create.Path, create.Line)
+	}
+	if create.Source != models.SourceSpec || create.FrameworkVersion != "2.0" || create.Version != "v1" {
+		t.Errorf("create source %s, framework version %s, version %s", create.Source, create.FrameworkVersion, create.Version)
+	}
+	if create.Function != "createUser" || create.Summary != "Create a user" {
+		t.Errorf("create function %q, summary %q", create.Function, create.Summary)
+	}
+
+	// The body parameter becomes the request schema, not a parameter
+	if len(create.Parameters) != 1 || create.Parameters[0].Name != "X-Request-ID" || create.Parameters[0].In != models.ParamHeader {
+		t.Errorf("create parameters = %+v, want the X-Request-ID header only", create.Parameters)
+	}
+	if create.Request == nil || create.Request.Name != "User" {
+		t.Fatalf("create request = %+v, want User", create.Request)
+	}
+	wantFields := []models.Field{{Name: "email", Type: "string", Required: true}, {Name: "name", Type: "string"}}
+	if len(create.Request.Fields) != len(wantFields) {
+		t.Fatalf("User fields = %+v, want %+v", create.Request.Fields, wantFields)
+	}
+	for i, field := range create.Request.Fields {
+		if field != wantFields[i] {
+			t.Errorf("User field %d = %+v, want %+v", i, field, wantFields[i])
+		}
+	}
+	if create.Response == nil || create.Response.Name != "User" {
+		t.Errorf("create response = %+v, want User", create.Response)
+	}
+	if len(create.StatusCodes) != 2 || create.StatusCodes[0] != 201 || create.StatusCodes[1] != 400 {
+		t.Errorf("create status codes = %v, want [201 400]", create.StatusCodes)
+	}
+
+	get := endpoints[1]
+	if get.Method != "GET" || get.Path != "/api/v1/users/{id}" {
+		t.Errorf("get = %s %s", get.Method, get.Path)
+	}
+	if get.Summary != "Returns a user." || get.SummarySource != models.SummaryFromDocs {
+		t.Errorf("get summary %q from %q", get.Summary, get.SummarySource)
+	}
+	// Path item parameters apply to every operation
+	if len(get.Parameters) != 1 || get.Parameters[0] != (models.Parameter{Name: "id", In: models.ParamPath, Type: "integer(int64)", Required: true}) {
+		t.Errorf("get parameters = %+v", get.Parameters)
+	}
+	// A reference to a missing definition keeps its name but has no fields
+	if get.Response == nil || get.Response.Name != "Missing" || len(get.Response.Fields) != 0 {
+		t.Errorf("get response = %+v, want Missing without fields", get.Response)
+	}
+}
+
+func TestOpenAPIDetectorOpenAPI3(t *testing.T) {
+	endpoints, err := NewOpenAPIDetector().Detect([]detector.File{
+		{Path: "/repo/openapi.yaml", Content: []byte(openAPI3Spec)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoints) != 2 {
+		t.Fatalf("got %d endpoints, want 2", len(endpoints))
+	}
+
+	list, create := endpoints[0], endpoints[1]
+	if list.Path != "/v2/orders" || list.Version != "v2" || list.FrameworkVersion != "3.0.3" {
+		t.Errorf("list = %s (version %s, framework version %s)", list.Path, list.Version, list.FrameworkVersion)
+	}
+	if len(list.Tags) != 1 || list.Tags[0] != "orders" {
+		t.Errorf("list tags = %v", list.Tags)
+	}
+	if len(list.Parameters) != 1 || list.Parameters[0] != (models.Parameter{Name: "page", In: models.ParamQuery, Type: "integer", Default: "1"}) {
+		t.Errorf("list parameters = %+v", list.Parameters)
+	}
+	// JSON is preferred over other media types, and arrays list their items' fields
+	if list.Response == nil || list.Response.Name != "[]Order" || len(list.Response.Fields) != 2 {
+		t.Errorf("list response = %+v, want []Order with its fields", list.Response)
+	}
+	// An empty security requirement overrides the global one
+	if list.AuthRequired || len(list.Middleware) != 0 {
+		t.Errorf("list middleware = %v, want none", list.Middleware)
+	}
+
+	// OpenAPI 3 declares the body in requestBody rather than in a parameter
+	if len(create.Parameters) != 0 {
+		t.Errorf("create parameters = %+v, want none", create.Parameters)
+	}
+	if create.Request == nil || create.Request.Name != "Order" || len(create.Request.Fields) != 2 {
+		t.Fatalf("create request = %+v, want Order with its fields", create.Request)
+	}
+	if field := create.Request.Fields[0]; field != (models.Field{Name: "id", Type: "string(uuid)"}) {
+		t.Errorf("Order id = %+v", field)
+	}
+	if create.Response != nil {
+		t.Errorf("create response = %+v, want none", create.Response)
+	}
+	// The default response has no single status
+	if len(create.StatusCodes) != 1 || create.StatusCodes[0] != 201 {
+		t.Errorf("create status codes = %v, want [201]", create.StatusCodes)
+	}
+	if !create.AuthRequired || len(create.Middleware) != 1 || create.Middleware[0] != "@Security bearerAuth" {
+		t.Errorf("create middleware = %v, want the global bearerAuth", create.Middleware)
+	}
+}
+
+func TestOpenAPIDetectorFiles(t *testing.T) {
+	files := []detector.File{
+		{Path: "/repo/min.json", Content: []byte(`{"openapi":"3.1.0","paths":{"/health":{"get":{"responses":{"204":{"description":"OK"}}}}}}`)},
+		{Path: "/repo/config.yaml", Content: []byte("server:\n  port: 8080\npaths:\n  /ignored:\n    get: {}\n")},
+		{Path: "/repo/docs/openapi.yaml", Content: []byte("openapi: 3.0.0\npaths: [\n")},
+	}
+
+	endpoints, err := NewOpenAPIDetector().Detect(files)
+	if err == nil {
+		t.Error("invalid document did not return an error")
+	}
+	// Valid documents are still reported alongside the error
+	if len(endpoints) != 1 || endpoints[0].Path != "/health" || endpoints[0].FrameworkVersion != "3.1.0" {
+		for _, endpoint := range endpoints {
+			t.Logf("%s %s in %s", endpoint.Method, endpoint.Path, endpoint.File)
+		}
+		t.Errorf("got %d endpoints, want GET /health from the minified document", len(endpoints))
+	}
+}
+
+func TestOpenAPIDetectorSupports(t *testing.T) {
+	d := newOpenAPIDetector(rootedFS{root: "/repo", fsys: fstest.MapFS{
+		"api/openapi.yaml":         {Data: []byte(openAPI3Spec)},
+		"api/v1.json":              {Data: []byte(`{"swagger":"2.0","paths":{}}`)},
+		"package-lock.json":        {Data: []byte(`{"name": "app", "lockfileVersion": 3, "packages": {}}`)},
+		".github/workflows/ci.yml": {Data: []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n")},
+		"late.yaml":                {Data: []byte(strings.Repeat("# padding\n", 500) + "openapi: 3.0.0\n")},
+		"main.go":                  {Data: []byte("// openapi: 3.0.0\npackage main\n")},
+	}})
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/repo/api/openapi.yaml", true},
+		{"/repo/api/v1.json", true},
+		{"/repo/package-lock.json", false},
+		{"/repo/.github/workflows/ci.yml", false},
+		{"/repo/late.yaml", false}, // The version key must be near the top
+		{"/repo/main.go", false},
+		{"/repo/missing.yaml", false},
+	}
+
+	for _, tt := range tests {
+		if got := d.Supports(tt.path); got != tt.want {
+			t.Errorf("Supports(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
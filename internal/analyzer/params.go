@@ -0,0 +1,556 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+// paramAccessor matches an expression in a handler body that reads a parameter
+type paramAccessor struct {
+	regex        *regexp.Regexp
+	in           string
+	required     bool
+	defaultIndex int // Capture group holding the default value, 0 if none
+}
+
+// paramAccessors lists the parameter accessors of frameworks whose handlers
+// read parameters in their body. The first capture group is the name.
+var paramAccessors = map[string][]paramAccessor{
+	"Gin": {
+		{regex: regexp.MustCompile(`\.DefaultQuery\(\s*"([^"]+)"\s*,\s*([^)]+?)\s*\)`), in: models.ParamQuery, defaultIndex: 2},
+		{regex: regexp.MustCompile(`\.(?:Query|GetQuery|QueryArray|GetQueryArray|QueryMap)\(\s*"([^"]+)"`), in: models.ParamQuery},
+		{regex: regexp.MustCompile(`\.(?:GetHeader|Header\.Get)\(\s*"([^"]+)"`), in: models.ParamHeader},
+		{regex: regexp.MustCompile(`\.Cookie\(\s*"([^"]+)"`), in: models.ParamCookie},
+	},
+	"Echo": {
+		{regex: regexp.MustCompile(`\.QueryParams?\(\s*"([^"]+)"`), in: models.ParamQuery},
+		{regex: regexp.MustCompile(`\.Header\.Get\(\s*"([^"]+)"`), in: models.ParamHeader},
+		{regex: regexp.MustCompile(`\.Cookie\(\s*"([^"]+)"`), in: models.ParamCookie},
+	},
+	"Express": {
+		{regex: regexp.MustCompile(`req\.query\.(\w+)`), in: models.ParamQuery},
+		{regex: regexp.MustCompile(`req\.query\[\s*['"]([^'"]+)['"]\s*\]`), in: models.ParamQuery},
+		{regex: regexp.MustCompile(`req\.(?:get|header)\(\s*['"]([^'"]+)['"]`), in: models.ParamHeader},
+		{regex: regexp.MustCompile(`req\.headers\[\s*['"]([^'"]+)['"]\s*\]`), in: models.ParamHeader},
+		{regex: regexp.MustCompile(`req\.headers\.(\w+)`), in: models.ParamHeader},
+		{regex: regexp.MustCompile(`req\.(?:signedC|c)ookies\.(\w+)`), in: models.ParamCookie},
+		{regex: regexp.MustCompile(`req\.(?:signedC|c)ookies\[\s*['"]([^'"]+)['"]\s*\]`), in: models.ParamCookie},
+	},
+	"Flask": {
+		{regex: regexp.MustCompile(`request\.args\.get\(\s*['"]([^'"]+)['"](?:\s*,\s*(?:default\s*=\s*)?([^,)=\s]+)\s*[,)])?`), in: models.ParamQuery, defaultIndex: 2},
+		{regex: regexp.MustCompile(`request\.args\.getlist\(\s*['"]([^'"]+)['"]`), in: models.ParamQuery},
+		{regex: regexp.MustCompile(`request\.args\[\s*['"]([^'"]+)['"]\s*\]`), in: models.ParamQuery, required: true},
+		{regex: regexp.MustCompile(`request\.headers\.get\(\s*['"]([^'"]+)['"]`), in: models.ParamHeader},
+		{regex: regexp.MustCompile(`request\.headers\[\s*['"]([^'"]+)['"]\s*\]`), in: models.ParamHeader, required: true},
+		{regex: regexp.MustCompile(`request\.cookies\.get\(\s*['"]([^'"]+)['"]`), in: models.ParamCookie},
+	},
+	"NestJS": {
+		{regex: regexp.MustCompile(`@Query\(\s*['"]([^'"]+)['"]`), in: models.ParamQuery},
+		{regex: regexp.MustCompile(`@Headers\(\s*['"]([^'"]+)['"]`), in: models.ParamHeader},
+	},
+	"ASP.NET": {
+		{regex: regexp.MustCompile(`Request\.Query\[\s*"([^"]+)"\s*\]`), in: models.ParamQuery},
+		{regex: regexp.MustCompile(`Request\.Headers\[\s*"([^"]+)"\s*\]`), in: models.ParamHeader},
+		{regex: regexp.MustCompile(`Request\.Cookies\[\s*"([^"]+)"\s*\]`), in: models.ParamCookie},
+	},
+	"Serverless": lambdaAccessors,
+	"AWS SAM":    lambdaAccessors,
+}
+
+// lambdaAccessors read the parameters of an API Gateway proxy event
+var lambdaAccessors = []paramAccessor{
+	{regex: regexp.MustCompile(`(?:queryStringParameters|multiValueQueryStringParameters)\??\.(\w+)`), in: models.ParamQuery},
+	{regex: regexp.MustCompile(`(?:queryStringParameters|multiValueQueryStringParameters)\??\.?\[\s*['"]([^'"]+)['"]\s*\]`), in: models.ParamQuery},
+	{regex: regexp.MustCompile(`queryStringParameters['"]\s*[\])](?:\s*or\s*\{\})?\)?\.get\(\s*['"]([^'"]+)['"]`), in: models.ParamQuery},
+	{regex: regexp.MustCompile(`headers\??\.?\[\s*['"]([^'"]+)['"]\s*\]`), in: models.ParamHeader},
+	{regex: regexp.MustCompile(`headers['"]\s*[\])](?:\s*or\s*\{\})?\)?\.get\(\s*['"]([^'"]+)['"]`), in: models.ParamHeader},
+}
+
+var (
+	expressDestructureRegex = regexp.MustCompile(`\{([^{}]*)\}\s*=\s*req\.(query|headers|cookies)\b`)
+	pathParamRegex          = regexp.MustCompile(`^(?::(\w+)(\?)?|\{\*?(\w+)\+?(?::([^}]+))?\}|<(?:(\w+):)?(\w+)>|\*(\w+))$`)
+	annotationArgsRegex     = regexp.MustCompile(`^@(\w+)(?:\(([^)]*)\))?\s*`)
+	attributeArgsRegex      = regexp.MustCompile(`^\[(\w+)(?:\(([^)]*)\))?\]\s*`)
+	annotationValueRegex    = regexp.MustCompile(`(?:^|,)\s*(?:(\w+)\s*=\s*)?"([^"]*)"`)
+	annotationBoolRegex     = regexp.MustCompile(`required\s*=\s*false`)
+)
+
+// extractParameters records the parameters an endpoint reads, starting with
+// the ones declared in its path
+func extractParameters(endpoint *models.Endpoint, h *handler) {
+	params := pathParameters(endpoint.Path)
+
+	if h != nil {
+		switch endpoint.Framework {
+		case "FastAPI":
+			params = fastAPIParameters(params, h)
+		case "Spring":
+			params = springParameters(params, h)
+		case "ASP.NET":
+			params = aspNetParameters(params, h)
+		}
+
+		body := h.bodyText()
+		for _, accessor := range paramAccessors[endpoint.Framework] {
+			for _, matches := range accessor.regex.FindAllStringSubmatch(body, -1) {
+				param := models.Parameter{Name: matches[1], In: accessor.in, Required: accessor.required}
+				if accessor.defaultIndex > 0 {
+					param.Default = matches[accessor.defaultIndex]
+				}
+				params = addParameter(params, param)
+			}
+		}
+
+		if endpoint.Framework == "Express" {
+			params = expressDestructuredParameters(params, body)
+		}
+	}
+
+	endpoint.Parameters = params
+}
+
+// pathParameters parses the parameters declared in a route path
+func pathParameters(path string) []models.Parameter {
+	var params []models.Parameter
+
+	for _, segment := range pathSegments(path) {
+		matches := pathParamRegex.FindStringSubmatch(segment)
+		if matches == nil {
+			continue
+		}
+
+		param := models.Parameter{In: models.ParamPath, Required: true}
+		switch {
+		case matches[1] != "": // :id or :id?
+			param.Name = matches[1]
+			param.Required = matches[2] == ""
+		case matches[3] != "": // {id} or {id:int}
+			param.Name = matches[3]
+			param.Type = matches[4]
+		case matches[6] != "": // <int:id>
+			param.Name = matches[6]
+			param.Type = matches[5]
+		default: // *path
+			param.Name = matches[7]
+		}
+		params = addParameter(params, param)
+	}
+
+	return params
+}
+
+// addParameter adds param unless a parameter with the same name and
+// location exists, in which case missing details are filled in
+func addParameter(params []models.Parameter, param models.Parameter) []models.Parameter {
+	param.Default = literalValue(param.Default)
+	for i := range params {
+		if params[i].In == param.In && params[i].Name == param.Name {
+			if params[i].Type == "" {
+				params[i].Type = param.Type
+			}
+			if params[i].Default == "" {
+				params[i].Default = param.Default
+			}
+			if params[i].Description == "" {
+				params[i].Description = param.Description
+			}
+			return params
+		}
+	}
+	return append(params, param)
+}
+
+// literalValue unquotes a default value, treating null-like values as no default
+func literalValue(value string) string {
+	value = strings.TrimSpace(value)
+	switch value {
+	case "null", "None", "nil", "undefined":
+		return ""
+	}
+	return strings.Trim(value, "\"'`")
+}
+
+// hasPathParameter reports whether params declares a path parameter name
+func hasPathParameter(params []models.Parameter, name string) bool {
+	for _, param := range params {
+		if param.In == models.ParamPath && param.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// signatureParams returns the top-level parameters of a declaration, up to
+// the parenthesis closing its parameter list
+func signatureParams(signature string) []string {
+	open := strings.Index(signature, "(")
+	if open < 0 {
+		return nil
+	}
+	depth := 0
+	for i := open; i < len(signature); i++ {
+		switch signature[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return splitParams(signature[open+1 : i])
+			}
+		}
+	}
+	return nil
+}
+
+// fastAPIParameters reads parameters from a FastAPI handler signature, where
+// Query(), Header(), Cookie() and Path() defaults or Annotated[] metadata
+// declare the location and unmarked scalar parameters are query parameters
+func fastAPIParameters(params []models.Parameter, h *handler) []models.Parameter {
+	for _, raw := range signatureParams(h.signature) {
+		name, paramType, defaultValue := splitPythonParam(raw)
+		if name == "" || name == "self" || strings.HasPrefix(name, "*") {
+			continue
+		}
+		paramType, marker := unwrapAnnotated(paramType)
+		switch paramType {
+		case "Request", "Response", "BackgroundTasks", "WebSocket":
+			continue
+		}
+
+		param := models.Parameter{Name: name, Type: paramType, In: models.ParamQuery}
+		call, args := splitCall(defaultValue)
+		if marker != "" {
+			call, args = splitCall(marker)
+		}
+		switch call {
+		case "Query":
+		case "Header":
+			param.In = models.ParamHeader
+			param.Name = strings.ReplaceAll(name, "_", "-")
+		case "Cookie":
+			param.In = models.ParamCookie
+		case "Path":
+			param.In = models.ParamPath
+		case "":
+			// Models and other classes are read from the body
+			if !isScalarAnnotation(paramType) && !hasPathParameter(params, name) {
+				continue
+			}
+			args = defaultValue
+		default:
+			// Depends(), Body(), Form(), File() and other non-parameter defaults
+			continue
+		}
+
+		if hasPathParameter(params, name) {
+			param.In = models.ParamPath
+			param.Name = name
+		}
+
+		firstArg := ""
+		if parts := splitParams(args); len(parts) > 0 && !strings.Contains(parts[0], "=") {
+			firstArg = parts[0]
+		}
+		if defaultArg := keywordArg(args, "default"); defaultArg != "" {
+			firstArg = defaultArg
+		}
+		if marker != "" {
+			// Annotated parameters take their default after the annotation
+			firstArg = defaultValue
+		}
+
+		switch {
+		case call == "" && defaultValue == "":
+			param.Required = true
+		case firstArg == "" && call != "":
+			param.Required = true
+		case firstArg == "...":
+			param.Required = true
+		case firstArg != "None":
+			param.Default = firstArg
+		}
+		if strings.HasPrefix(paramType, "Optional[") || strings.Contains(paramType, "None") {
+			param.Required = false
+		}
+		if param.In == models.ParamPath {
+			param.Required = true
+		}
+
+		params = addParameter(params, param)
+	}
+
+	return params
+}
+
+// pythonScalarTypes are the annotations FastAPI reads from the query string
+// when a parameter has no marker such as Query() or Body()
+var pythonScalarTypes = map[string]bool{
+	"str": true, "int": true, "float": true, "bool": true, "bytes": true,
+	"UUID": true, "datetime": true, "date": true, "time": true, "timedelta": true,
+	"Decimal": true, "EmailStr": true, "HttpUrl": true, "Literal": true,
+}
+
+// unwrapAnnotated splits Annotated[str, Header()] into the annotated type
+// and its FastAPI marker call, if any
+func unwrapAnnotated(paramType string) (string, string) {
+	inner, ok := strings.CutPrefix(paramType, "Annotated[")
+	if !ok || !strings.HasSuffix(inner, "]") {
+		return paramType, ""
+	}
+	parts := splitParams(strings.TrimSuffix(inner, "]"))
+	if len(parts) == 0 {
+		return paramType, ""
+	}
+	for _, part := range parts[1:] {
+		if call, _ := splitCall(part); call != "" {
+			return parts[0], part
+		}
+	}
+	return parts[0], ""
+}
+
+// isScalarAnnotation reports whether a type annotation is missing or a
+// scalar, possibly Optional or in a union with None
+func isScalarAnnotation(paramType string) bool {
+	if paramType == "" {
+		return true
+	}
+	if inner, ok := strings.CutPrefix(paramType, "Optional["); ok && strings.HasSuffix(inner, "]") {
+		return isScalarAnnotation(strings.TrimSuffix(inner, "]"))
+	}
+	if inner, ok := strings.CutPrefix(paramType, "Union["); ok && strings.HasSuffix(inner, "]") {
+		paramType = strings.ReplaceAll(strings.TrimSuffix(inner, "]"), ",", "|")
+	}
+
+	for _, member := range strings.Split(paramType, "|") {
+		member = strings.TrimSpace(member)
+		if idx := strings.Index(member, "["); idx >= 0 {
+			member = member[:idx]
+		}
+		if idx := strings.LastIndex(member, "."); idx >= 0 {
+			member = member[idx+1:]
+		}
+		if member != "None" && !pythonScalarTypes[member] {
+			return false
+		}
+	}
+	return true
+}
+
+// splitPythonParam splits "name: type = default" into its parts
+func splitPythonParam(raw string) (string, string, string) {
+	name, defaultValue := raw, ""
+	if idx := topLevelIndex(raw, '='); idx >= 0 {
+		name, defaultValue = raw[:idx], strings.TrimSpace(raw[idx+1:])
+	}
+	paramType := ""
+	if idx := strings.Index(name, ":"); idx >= 0 {
+		name, paramType = name[:idx], strings.TrimSpace(name[idx+1:])
+	}
+	return strings.TrimSpace(name), paramType, defaultValue
+}
+
+// splitCall splits "Query(1, alias='x')" into "Query" and "1, alias='x'"
+func splitCall(expr string) (string, string) {
+	open := strings.Index(expr, "(")
+	if open <= 0 || !strings.HasSuffix(expr, ")") {
+		return "", ""
+	}
+	name := expr[:open]
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return name, expr[open+1 : len(expr)-1]
+}
+
+// keywordArg returns the value of a keyword argument in a call's arguments
+func keywordArg(args, keyword string) string {
+	for _, arg := range splitParams(args) {
+		if key, value, ok := strings.Cut(arg, "="); ok && strings.TrimSpace(key) == keyword {
+			return strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return ""
+}
+
+// topLevelIndex returns the index of the first c outside brackets, or -1
+func topLevelIndex(s string, c byte) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(', '[', '<', '{':
+			depth++
+		case ')', ']', '>', '}':
+			depth--
+		case c:
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// springParameters reads @RequestParam, @RequestHeader, @CookieValue and
+// @PathVariable parameters from a Spring handler signature
+func springParameters(params []models.Parameter, h *handler) []models.Parameter {
+	for _, raw := range signatureParams(h.signature) {
+		raw = strings.TrimSpace(raw)
+		var annotation, args, description string
+		for {
+			matches := annotationArgsRegex.FindStringSubmatch(raw)
+			if matches == nil {
+				break
+			}
+			switch matches[1] {
+			case "RequestParam", "RequestHeader", "CookieValue", "PathVariable":
+				annotation, args = matches[1], matches[2]
+			case "Parameter", "ApiParam":
+				attrs := javaAttributes(matches[2])
+				description = attrs["description"] + attrs["value"]
+			}
+			raw = raw[len(matches[0]):]
+		}
+		if annotation == "" {
+			continue
+		}
+
+		parts := strings.Fields(raw)
+		if len(parts) < 2 {
+			continue
+		}
+		paramType, name := strings.Join(parts[:len(parts)-1], " "), parts[len(parts)-1]
+
+		param := models.Parameter{Name: name, Type: paramType, Required: true, Description: description}
+		for _, value := range annotationValueRegex.FindAllStringSubmatch(args, -1) {
+			switch value[1] {
+			case "", "value", "name":
+				param.Name = value[2]
+			case "defaultValue":
+				param.Default = value[2]
+				param.Required = false
+			}
+		}
+		if annotationBoolRegex.MatchString(args) || strings.HasPrefix(paramType, "Optional<") {
+			param.Required = false
+		}
+
+		switch annotation {
+		case "RequestParam":
+			param.In = models.ParamQuery
+		case "RequestHeader":
+			param.In = models.ParamHeader
+		case "CookieValue":
+			param.In = models.ParamCookie
+		case "PathVariable":
+			param.In = models.ParamPath
+			param.Required = true
+		}
+		params = addParameter(params, param)
+	}
+
+	return params
+}
+
+// aspNetParameters reads [FromQuery], [FromHeader] and [FromRoute]
+// parameters, treating undecorated simple parameters as route or query values
+func aspNetParameters(params []models.Parameter, h *handler) []models.Parameter {
+	for _, raw := range signatureParams(h.signature) {
+		raw = strings.TrimSpace(raw)
+		var attribute, args string
+		for {
+			matches := attributeArgsRegex.FindStringSubmatch(raw)
+			if matches == nil {
+				break
+			}
+			attribute, args = matches[1], matches[2]
+			raw = raw[len(matches[0]):]
+		}
+
+		declaration, defaultValue := raw, ""
+		if idx := strings.Index(raw, "="); idx >= 0 {
+			declaration, defaultValue = strings.TrimSpace(raw[:idx]), strings.Trim(strings.TrimSpace(raw[idx+1:]), `"`)
+		}
+		parts := strings.Fields(declaration)
+		if len(parts) < 2 {
+			continue
+		}
+		paramType, name := strings.Join(parts[:len(parts)-1], " "), parts[len(parts)-1]
+
+		param := models.Parameter{
+			Name:     name,
+			Type:     paramType,
+			Default:  defaultValue,
+			Required: defaultValue == "" && !strings.HasSuffix(paramType, "?"),
+		}
+		if alias := keywordArg(args, "Name"); alias != "" {
+			param.Name = alias
+		}
+
+		switch attribute {
+		case "FromQuery":
+			param.In = models.ParamQuery
+		case "FromHeader":
+			param.In = models.ParamHeader
+		case "FromRoute":
+			param.In = models.ParamPath
+		case "":
+			if !isSimpleType(paramType) {
+				continue
+			}
+			param.In = models.ParamQuery
+			if hasPathParameter(params, name) {
+				param.In = models.ParamPath
+			}
+		default:
+			// [FromBody], [FromServices], [FromForm]
+			continue
+		}
+		params = addParameter(params, param)
+	}
+
+	return params
+}
+
+// isSimpleType reports whether a C# type binds from a single string value
+func isSimpleType(typeName string) bool {
+	switch strings.TrimSuffix(typeName, "?") {
+	case "string", "int", "long", "short", "bool", "double", "float", "decimal",
+		"Guid", "DateTime", "DateTimeOffset", "DateOnly", "TimeOnly", "byte", "char", "uint", "ulong":
+		return true
+	}
+	return false
+}
+
+// expressDestructuredParameters reads parameters destructured from
+// req.query, req.headers or req.cookies, e.g. const { page = 1 } = req.query
+func expressDestructuredParameters(params []models.Parameter, body string) []models.Parameter {
+	locations := map[string]string{
+		"query":   models.ParamQuery,
+		"headers": models.ParamHeader,
+		"cookies": models.ParamCookie,
+	}
+
+	for _, matches := range expressDestructureRegex.FindAllStringSubmatch(body, -1) {
+		for _, entry := range splitParams(matches[1]) {
+			name, defaultValue, _ := strings.Cut(entry, "=")
+			name = strings.TrimSpace(name)
+			if idx := strings.Index(name, ":"); idx >= 0 {
+				name = strings.TrimSpace(name[:idx])
+			}
+			name = strings.Trim(name, `'"`)
+			if name == "" || strings.HasPrefix(name, "...") {
+				continue
+			}
+			params = addParameter(params, models.Parameter{
+				Name:    name,
+				In:      locations[matches[2]],
+				Default: strings.TrimSpace(defaultValue),
+			})
+		}
+	}
+
+	return params
+}
@@ -1,21 +1,58 @@
 package analyzer
 
-import "regexp"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tarantino19/restgo/internal/config"
+	"github.com/tarantino19/restgo/pkg/models"
+)
 
 // FrameworkPatterns holds regex patterns for different frameworks
 type FrameworkPatterns struct {
 	Name         string
-	FilePatterns []string // File extensions to look for
+	FilePatterns []string         // File extensions to look for
+	Imports      []*regexp.Regexp // Source imports that identify the framework
+	Dependencies []string         // Manifest package names that identify the framework
 	Patterns     []Pattern
+	Custom       bool // User-defined; applies to every file with its extensions
 }
 
 // Pattern represents a regex pattern for finding endpoints
 type Pattern struct {
-	Regex          *regexp.Regexp
-	MethodIndex    int // Capture group index for HTTP method
-	PathIndex      int // Capture group index for path
-	FunctionIndex  int // Capture group index for function name (optional)
-	IsMethodFirst  bool // If true, method comes before path in regex
+	Regex         *regexp.Regexp
+	MethodIndex   int    // Capture group index for HTTP method
+	PathIndex     int    // Capture group index for path
+	FunctionIndex int    // Capture group index for function name (optional)
+	IsMethodFirst bool   // If true, method comes before path in regex
+	DefaultMethod string // Method used when MethodIndex captures nothing (defaults to GET)
+	DefaultPath   string // Path used when PathIndex captures nothing (e.g. NestJS @Get())
+	Kind          string // Endpoint kind, e.g. models.KindWebSocket (defaults to REST)
+	// PathExpression marks PathIndex as capturing a source expression, such
+	// as BASE + "/users", resolved against the declared constants
+	PathExpression bool
+}
+
+// goMethodCallPatterns match routes registered with upper-case method calls
+// such as r.GET("/path", handler), shared by the Go routers using them. A
+// file matching several of those frameworks applies them only once.
+var goMethodCallPatterns = []Pattern{
+	{
+		// r.GET("/path", handler) or api.GET("/path", handler) on a group
+		Regex:         regexp.MustCompile(`\b\w+\.(GET|POST|PUT|DELETE|PATCH)\s*\(\s*["` + "`" + `]([^"` + "`" + `]+)["` + "`" + `]`),
+		MethodIndex:   1,
+		PathIndex:     2,
+		IsMethodFirst: true,
+	},
+	{
+		// api.GET(apiPrefix+"/items", handler)
+		Regex:          regexp.MustCompile(`\b\w+\.(GET|POST|PUT|DELETE|PATCH)\s*\(\s*` + goPathExpr + `\s*,`),
+		MethodIndex:    1,
+		PathIndex:      2,
+		IsMethodFirst:  true,
+		PathExpression: true,
+	},
 }
 
 // GetAllPatterns returns patterns for all supported frameworks
@@ -25,6 +62,10 @@ func GetAllPatterns() []FrameworkPatterns {
 		{
 			Name:         "Express",
 			FilePatterns: []string{".js", ".ts", ".mjs"},
+			Imports: []*regexp.Regexp{
+				regexp.MustCompile(`require\(\s*['"]express['"]\s*\)|from\s+['"]express['"]`),
+			},
+			Dependencies: []string{"express"},
 			Patterns: []Pattern{
 				{
 					// app.get('/path', handler)
@@ -47,12 +88,82 @@ func GetAllPatterns() []FrameworkPatterns {
 					PathIndex:     1,
 					IsMethodFirst: false,
 				},
+				{
+					// app.ws('/path', handler) with express-ws
+					Regex:         regexp.MustCompile(`\b(?:app|router)\.ws\s*\(\s*['"\` + "`" + `]([^'"\` + "`" + `]+)['"\` + "`" + `]`),
+					PathIndex:     1,
+					DefaultMethod: "GET",
+					Kind:          models.KindWebSocket,
+				},
+				{
+					// app.use('/graphql', graphqlHTTP({ schema }))
+					Regex:         regexp.MustCompile(`\b(?:app|router)\.use\s*\(\s*['"\` + "`" + `]([^'"\` + "`" + `]*/graphql)['"\` + "`" + `]`),
+					PathIndex:     1,
+					DefaultMethod: "POST",
+					Kind:          models.KindGraphQL,
+				},
+				{
+					// router.get(API_BASE + '/users', handler)
+					Regex:          regexp.MustCompile(`\b(?:app|router)\.(get|post|put|delete|patch|options|head)\s*\(\s*` + jsPathExpr + `\s*,`),
+					MethodIndex:    1,
+					PathIndex:      2,
+					IsMethodFirst:  true,
+					PathExpression: true,
+				},
+			},
+		},
+		// Socket.IO / Node.js
+		{
+			Name:         "Socket.IO",
+			FilePatterns: []string{".js", ".ts", ".mjs"},
+			Imports: []*regexp.Regexp{
+				regexp.MustCompile(`require\(\s*['"]socket\.io['"]\s*\)|from\s+['"]socket\.io['"]`),
+			},
+			Dependencies: []string{"socket.io"},
+			Patterns: []Pattern{
+				{
+					// socket.on('chat message', handler)
+					Regex:         regexp.MustCompile(`\bsocket\.on\s*\(\s*['"\` + "`" + `]([^'"\` + "`" + `]+)['"\` + "`" + `]`),
+					PathIndex:     1,
+					DefaultMethod: "EVENT",
+					Kind:          models.KindWebSocket,
+				},
+			},
+		},
+		// NestJS / TypeScript
+		{
+			Name:         "NestJS",
+			FilePatterns: []string{".ts", ".js"},
+			Imports: []*regexp.Regexp{
+				regexp.MustCompile(`from\s+['"]@nestjs/(?:common|websockets)['"]`),
+			},
+			Dependencies: []string{"@nestjs/core", "@nestjs/common", "@nestjs/websockets"},
+			Patterns: []Pattern{
+				{
+					// @Get(':id') inside a @Controller('users') class
+					Regex:         regexp.MustCompile(`@(Get|Post|Put|Delete|Patch|Options|Head|All)\s*\(\s*(?:['"\` + "`" + `]([^'"\` + "`" + `]*)['"\` + "`" + `])?\s*\)`),
+					MethodIndex:   1,
+					PathIndex:     2,
+					IsMethodFirst: true,
+					DefaultPath:   "/",
+				},
+				{
+					// @SubscribeMessage('events') in a @WebSocketGateway class
+					Regex:         regexp.MustCompile(`@SubscribeMessage\s*\(\s*['"\` + "`" + `]([^'"\` + "`" + `]+)['"\` + "`" + `]`),
+					PathIndex:     1,
+					DefaultMethod: "EVENT",
+					Kind:          models.KindWebSocket,
+				},
 			},
 		},
 		// Flask / Python
 		{
 			Name:         "Flask",
 			FilePatterns: []string{".py"},
+			Imports: []*regexp.Regexp{
+				regexp.MustCompile(`(?m)^\s*(?:from|import)\s+flask\b`),
+			},
+			Dependencies: []string{"flask"},
 			Patterns: []Pattern{
 				{
 					// @app.route('/path', methods=['GET'])
@@ -68,12 +179,24 @@ func GetAllPatterns() []FrameworkPatterns {
 					PathIndex:     1,
 					IsMethodFirst: false,
 				},
+				{
+					// @app.route(PREFIX + '/path') or @app.route(f'{PREFIX}/path')
+					Regex:          regexp.MustCompile(`@\w+\.route\s*\(\s*` + pyPathExpr + `\s*(?:\)|,\s*methods\s*=\s*\[['"](\w+)['"]\])`),
+					MethodIndex:    2,
+					PathIndex:      1,
+					IsMethodFirst:  false,
+					PathExpression: true,
+				},
 			},
 		},
 		// FastAPI / Python
 		{
 			Name:         "FastAPI",
 			FilePatterns: []string{".py"},
+			Imports: []*regexp.Regexp{
+				regexp.MustCompile(`(?m)^\s*(?:from|import)\s+fastapi\b`),
+			},
+			Dependencies: []string{"fastapi"},
 			Patterns: []Pattern{
 				{
 					// @app.get("/path")
@@ -89,12 +212,31 @@ func GetAllPatterns() []FrameworkPatterns {
 					PathIndex:     2,
 					IsMethodFirst: true,
 				},
+				{
+					// @app.websocket("/ws")
+					Regex:         regexp.MustCompile(`@(?:app|router)\.websocket\s*\(\s*["']([^"']+)["']`),
+					PathIndex:     1,
+					DefaultMethod: "GET",
+					Kind:          models.KindWebSocket,
+				},
+				{
+					// @router.get(f"{PREFIX}/path") or @app.get(PREFIX + "/path")
+					Regex:          regexp.MustCompile(`@(?:app|router)\.(get|post|put|delete|patch)\s*\(\s*` + pyPathExpr + `\s*[,)]`),
+					MethodIndex:    1,
+					PathIndex:      2,
+					IsMethodFirst:  true,
+					PathExpression: true,
+				},
 			},
 		},
 		// Spring Boot / Java
 		{
 			Name:         "Spring",
 			FilePatterns: []string{".java"},
+			Imports: []*regexp.Regexp{
+				regexp.MustCompile(`(?m)^\s*import\s+org\.springframework\.`),
+			},
+			Dependencies: []string{"spring-boot-starter-web", "spring-boot-starter-webflux", "spring-webmvc", "spring-web", "org.springframework.boot", "spring-boot-starter-parent"},
 			Patterns: []Pattern{
 				{
 					// @GetMapping("/path")
@@ -110,47 +252,44 @@ func GetAllPatterns() []FrameworkPatterns {
 					PathIndex:     1,
 					IsMethodFirst: false,
 				},
+				{
+					// @GetMapping(Paths.USERS + "/{id}")
+					Regex:          regexp.MustCompile(`@(Get|Post|Put|Delete|Patch)Mapping\s*\(\s*(?:(?:value|path)\s*=\s*)?` + javaPathExpr + `\s*[,)]`),
+					MethodIndex:    1,
+					PathIndex:      2,
+					IsMethodFirst:  true,
+					PathExpression: true,
+				},
 			},
 		},
 		// Gin / Go
 		{
 			Name:         "Gin",
 			FilePatterns: []string{".go"},
-			Patterns: []Pattern{
-				{
-					// router.GET("/path", handler)
-					Regex:         regexp.MustCompile(`router\.(GET|POST|PUT|DELETE|PATCH)\s*\(\s*["` + "`" + `]([^"` + "`" + `]+)["` + "`" + `]`),
-					MethodIndex:   1,
-					PathIndex:     2,
-					IsMethodFirst: true,
-				},
-				{
-					// r.GET("/path", handler)
-					Regex:         regexp.MustCompile(`\br\.(GET|POST|PUT|DELETE|PATCH)\s*\(\s*["` + "`" + `]([^"` + "`" + `]+)["` + "`" + `]`),
-					MethodIndex:   1,
-					PathIndex:     2,
-					IsMethodFirst: true,
-				},
+			Imports: []*regexp.Regexp{
+				regexp.MustCompile(`"github\.com/gin-gonic/gin"`),
 			},
+			Dependencies: []string{"github.com/gin-gonic/gin"},
+			Patterns:     goMethodCallPatterns,
 		},
 		// Echo / Go
 		{
 			Name:         "Echo",
 			FilePatterns: []string{".go"},
-			Patterns: []Pattern{
-				{
-					// e.GET("/path", handler)
-					Regex:         regexp.MustCompile(`e\.(GET|POST|PUT|DELETE|PATCH)\s*\(\s*["` + "`" + `]([^"` + "`" + `]+)["` + "`" + `]`),
-					MethodIndex:   1,
-					PathIndex:     2,
-					IsMethodFirst: true,
-				},
+			Imports: []*regexp.Regexp{
+				regexp.MustCompile(`"github\.com/labstack/echo(?:/v\d+)?"`),
 			},
+			Dependencies: []string{"github.com/labstack/echo/v4", "github.com/labstack/echo/v5", "github.com/labstack/echo"},
+			Patterns:     goMethodCallPatterns,
 		},
 		// Ruby on Rails
 		{
 			Name:         "Rails",
 			FilePatterns: []string{".rb"},
+			Imports: []*regexp.Regexp{
+				regexp.MustCompile(`Rails\.application\.routes\.draw`),
+			},
+			Dependencies: []string{"rails", "railties"},
 			Patterns: []Pattern{
 				{
 					// get '/path', to: 'controller#action'
@@ -172,6 +311,10 @@ func GetAllPatterns() []FrameworkPatterns {
 		{
 			Name:         "ASP.NET",
 			FilePatterns: []string{".cs"},
+			Imports: []*regexp.Regexp{
+				regexp.MustCompile(`(?m)^\s*using\s+Microsoft\.AspNetCore\.`),
+			},
+			Dependencies: []string{"Microsoft.AspNetCore.App", "Microsoft.NET.Sdk.Web", "Microsoft.AspNetCore.Mvc"},
 			Patterns: []Pattern{
 				{
 					// [HttpGet("/path")]
@@ -180,6 +323,14 @@ func GetAllPatterns() []FrameworkPatterns {
 					PathIndex:     2,
 					IsMethodFirst: true,
 				},
+				{
+					// [HttpGet(Routes.Users + "/{id}")]
+					Regex:          regexp.MustCompile(`\[Http(Get|Post|Put|Delete|Patch)\s*\(\s*` + javaPathExpr + `\s*\)`),
+					MethodIndex:    1,
+					PathIndex:      2,
+					IsMethodFirst:  true,
+					PathExpression: true,
+				},
 				{
 					// [Route("api/[controller]")]
 					Regex:         regexp.MustCompile(`\[Route\s*\(\s*["']([^"']+)["']\s*\)`),
@@ -190,4 +341,77 @@ func GetAllPatterns() []FrameworkPatterns {
 			},
 		},
 	}
-} 
\ No newline at end of file
+}
+
+// CompilePatterns converts user-defined pattern declarations into framework
+// patterns, grouping declarations that share a name
+func CompilePatterns(defs []config.PatternConfig) ([]FrameworkPatterns, error) {
+	var frameworks []FrameworkPatterns
+	byName := make(map[string]int)
+
+	for _, def := range defs {
+		re, err := regexp.Compile(def.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex for %s: %w", def.Name, err)
+		}
+
+		idx, ok := byName[def.Name]
+		if !ok {
+			idx = len(frameworks)
+			byName[def.Name] = idx
+			frameworks = append(frameworks, FrameworkPatterns{Name: def.Name, Custom: true})
+		}
+
+		framework := &frameworks[idx]
+		framework.FilePatterns = appendMissing(framework.FilePatterns, def.Extensions...)
+		framework.Patterns = append(framework.Patterns, Pattern{
+			Regex:         re,
+			MethodIndex:   def.MethodIndex,
+			PathIndex:     def.PathIndex,
+			FunctionIndex: def.FunctionIndex,
+			IsMethodFirst: def.MethodIndex > 0 && def.MethodIndex < def.PathIndex,
+			DefaultMethod: strings.ToUpper(def.DefaultMethod),
+		})
+	}
+
+	return frameworks, nil
+}
+
+// MergePatterns adds custom frameworks to the built-in ones. A custom
+// framework named like a built-in one reports its endpoints under the
+// built-in's name, so they are enriched the same way, but unlike the
+// built-in it is not gated on imports or dependencies.
+func MergePatterns(builtins, custom []FrameworkPatterns) []FrameworkPatterns {
+	merged := append([]FrameworkPatterns{}, builtins...)
+
+	for _, framework := range custom {
+		for _, builtin := range builtins {
+			if strings.EqualFold(builtin.Name, framework.Name) {
+				framework.Name = builtin.Name
+				// Only used to report the declared framework version
+				framework.Dependencies = builtin.Dependencies
+				break
+			}
+		}
+		merged = append(merged, framework)
+	}
+
+	return merged
+}
+
+// appendMissing appends the values not already present in list
+func appendMissing(list []string, values ...string) []string {
+	for _, value := range values {
+		exists := false
+		for _, existing := range list {
+			if existing == value {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			list = append(list, value)
+		}
+	}
+	return list
+}
@@ -0,0 +1,146 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+// PostProcess merges duplicate detections of the same source location and
+// reports routes that collide with each other
+func (a *Analyzer) PostProcess(endpoints []*models.Endpoint) ([]*models.Endpoint, []models.Conflict) {
+	merged := mergeDuplicates(endpoints)
+	return merged, findConflicts(merged)
+}
+
+// mergeDuplicates collapses endpoints detected more than once at the same
+// location (e.g. a line matched by two patterns of the same framework)
+func mergeDuplicates(endpoints []*models.Endpoint) []*models.Endpoint {
+	var result []*models.Endpoint
+	seen := make(map[string]*models.Endpoint)
+
+	for _, endpoint := range endpoints {
+		key := fmt.Sprintf("%s:%d:%s:%s", endpoint.File, endpoint.Line, endpoint.Method, normalizePath(endpoint.Path))
+		if existing, ok := seen[key]; ok {
+			if existing.Function == "" {
+				existing.Function = endpoint.Function
+			}
+			continue
+		}
+		seen[key] = endpoint
+		result = append(result, endpoint)
+	}
+
+	return result
+}
+
+// findConflicts reports identical routes registered by different handlers
+// and parameterized routes that shadow later literal ones in the same file
+func findConflicts(endpoints []*models.Endpoint) []models.Conflict {
+	var conflicts []models.Conflict
+
+	for i, first := range endpoints {
+		if first.Method == "RESOURCE" {
+			continue
+		}
+		firstSegments := pathSegments(first.Path)
+
+		for _, second := range endpoints[i+1:] {
+			// Services of a monorepo, and apps built on different frameworks,
+			// are deployed separately and may reuse routes. A spec and the code
+			// implementing it declare the same routes, and tests register
+			// routes that mirror the real ones.
+			if second.Method != first.Method || second.Service != first.Service || second.Framework != first.Framework ||
+				second.Source != first.Source || second.Test != first.Test {
+				continue
+			}
+			secondSegments := pathSegments(second.Path)
+
+			if normalizePath(first.Path) == normalizePath(second.Path) {
+				conflicts = append(conflicts, models.Conflict{Kind: models.ConflictDuplicate, First: first, Second: second})
+				continue
+			}
+
+			// Registration order is only known within a single file
+			if first.File != second.File {
+				continue
+			}
+			earlier, later := first, second
+			earlierSegments, laterSegments := firstSegments, secondSegments
+			if second.Line < first.Line {
+				earlier, later = second, first
+				earlierSegments, laterSegments = secondSegments, firstSegments
+			}
+			if shadows(earlierSegments, laterSegments) {
+				conflicts = append(conflicts, models.Conflict{Kind: models.ConflictShadowed, First: earlier, Second: later})
+			}
+		}
+	}
+
+	return conflicts
+}
+
+// shadows reports whether a route with the earlier segments matches every
+// request the later route would match, through at least one parameter
+func shadows(earlier, later []string) bool {
+	usedParam := false
+
+	for i, segment := range earlier {
+		if isWildcardSegment(segment) {
+			return i < len(later)
+		}
+		if i >= len(later) {
+			return false
+		}
+		switch {
+		case isParamSegment(segment):
+			if !isParamSegment(later[i]) {
+				usedParam = true
+			}
+		case segment != later[i]:
+			return false
+		}
+	}
+
+	return usedParam && len(earlier) == len(later)
+}
+
+// normalizePath rewrites a route so that equivalent paths from different
+// frameworks compare equal (e.g. /users/:id, /users/{id} and /users/<int:id>)
+func normalizePath(path string) string {
+	segments := pathSegments(path)
+	for i, segment := range segments {
+		switch {
+		case isWildcardSegment(segment):
+			segments[i] = "*"
+		case isParamSegment(segment):
+			segments[i] = "{}"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// pathSegments splits a route into its non-empty segments
+func pathSegments(path string) []string {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// isParamSegment reports whether a path segment is a route parameter
+func isParamSegment(segment string) bool {
+	return strings.HasPrefix(segment, ":") ||
+		(strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")) ||
+		(strings.HasPrefix(segment, "<") && strings.HasSuffix(segment, ">"))
+}
+
+// isWildcardSegment reports whether a path segment matches the rest of the path
+func isWildcardSegment(segment string) bool {
+	return strings.HasPrefix(segment, "*") || segment == "{*}" || strings.HasPrefix(segment, "{*") ||
+		strings.HasPrefix(segment, "<path:")
+}
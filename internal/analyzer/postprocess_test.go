@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+func TestFindConflicts(t *testing.T) {
+	endpoint := func(framework, file string, line int, method, path string) *models.Endpoint {
+		return &models.Endpoint{Source: models.SourceCode, Framework: framework, File: file, Line: line, Method: method, Path: path}
+	}
+
+	tests := []struct {
+		name      string
+		endpoints []*models.Endpoint
+		want      []string // Kind of each conflict
+	}{
+		{
+			name: "duplicate across files",
+			endpoints: []*models.Endpoint{
+				endpoint("Gin", "users.go", 10, "GET", "/users/:id"),
+				endpoint("Gin", "admin.go", 20, "GET", "/users/{id}"),
+			},
+			want: []string{models.ConflictDuplicate},
+		},
+		{
+			name: "different frameworks",
+			endpoints: []*models.Endpoint{
+				endpoint("Flask", "app.py", 5, "GET", "/users/<id>"),
+				endpoint("Gin", "main.go", 12, "GET", "/users/:id"),
+			},
+		},
+		{
+			name: "different methods",
+			endpoints: []*models.Endpoint{
+				endpoint("Express", "app.js", 1, "GET", "/users"),
+				endpoint("Express", "app.js", 2, "POST", "/users"),
+			},
+		},
+		{
+			name: "parameter shadows a later literal",
+			endpoints: []*models.Endpoint{
+				endpoint("Express", "app.js", 1, "GET", "/users/:id"),
+				endpoint("Express", "app.js", 2, "GET", "/users/me"),
+			},
+			want: []string{models.ConflictShadowed},
+		},
+		{
+			name: "literal registered first",
+			endpoints: []*models.Endpoint{
+				endpoint("Express", "app.js", 1, "GET", "/users/me"),
+				endpoint("Express", "app.js", 2, "GET", "/users/:id"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		conflicts := findConflicts(tt.endpoints)
+		if len(conflicts) != len(tt.want) {
+			t.Errorf("%s: %d conflicts, want %d", tt.name, len(conflicts), len(tt.want))
+			continue
+		}
+		for i, conflict := range conflicts {
+			if conflict.Kind != tt.want[i] {
+				t.Errorf("%s: conflict %s, want %s", tt.name, conflict.Kind, tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package analyzer
+
+import (
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+// applyRoutePrefix prepends the path prefix of the Go router group or
+// NestJS controller an endpoint is registered in
+func applyRoutePrefix(endpoint *models.Endpoint, src *sourceFile, pkg *sourcePackage) {
+	line := endpoint.Line - 1
+	prefix := ""
+
+	switch endpoint.Framework {
+	case "Gin", "Echo":
+		prefix = groupPrefix(src, pkg, line)
+	case "NestJS":
+		prefix = controllerPrefix(src, line)
+	}
+
+	if prefix != "" {
+		endpoint.Path = joinRoutePath(prefix, endpoint.Path)
+	}
+}
+
+// groupPrefix joins the prefixes of the router groups enclosing the route
+// registered at line
+func groupPrefix(src *sourceFile, pkg *sourcePackage, line int) string {
+	text, loc := routeCall(src, line)
+	if loc == nil {
+		return ""
+	}
+
+	scopes := routeScopes(src, pkg, line)
+	prefix := ""
+	visited := make(map[string]bool)
+	for name := text[loc[2]:loc[3]]; name != "" && !visited[name]; {
+		visited[name] = true
+		scope, ok := scopes[name]
+		if !ok {
+			break
+		}
+		prefix = joinRoutePath(scope.prefix, prefix)
+		name = scope.parent
+	}
+	return prefix
+}
+
+// controllerPrefix returns the path of the @Controller decorating the class
+// that declares the handler at line
+func controllerPrefix(src *sourceFile, line int) string {
+	classLine := enclosingClass(src, line)
+	if classLine < 0 {
+		return ""
+	}
+	if matches := controllerRegex.FindStringSubmatch(classAnnotations(src.lines, classLine)); matches != nil {
+		return matches[1] + matches[2]
+	}
+	return ""
+}
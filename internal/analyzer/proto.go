@@ -0,0 +1,371 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/tarantino19/restgo/pkg/detector"
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+var (
+	protoPackageRegex = regexp.MustCompile(`^\s*package\s+([\w.]+)\s*;`)
+	protoServiceRegex = regexp.MustCompile(`^\s*service\s+(\w+)\s*\{`)
+	protoRPCRegex     = regexp.MustCompile(`^\s*rpc\s+(\w+)\s*\(\s*(stream\s+)?([\w.]+)\s*\)\s*returns\s*\(\s*(stream\s+)?([\w.]+)\s*\)`)
+	protoMessageRegex = regexp.MustCompile(`^\s*message\s+(\w+)\s*\{`)
+	protoFieldRegex   = regexp.MustCompile(`^\s*(optional\s+|repeated\s+|required\s+)?(map\s*<[^>]+>|[\w.]+)\s+(\w+)\s*=\s*\d+`)
+
+	protoHTTPOptionRegex   = regexp.MustCompile(`option\s*\(\s*google\.api\.http\s*\)\s*=\s*\{`)
+	protoAdditionalRegex   = regexp.MustCompile(`\badditional_bindings\s*:?\s*\{`)
+	protoBindingRegex      = regexp.MustCompile(`\b(get|put|post|delete|patch)\s*:\s*"([^"]+)"`)
+	protoCustomRegex       = regexp.MustCompile(`\bcustom\s*:?\s*\{\s*kind\s*:\s*"(\w+)"\s*,?\s*path\s*:\s*"([^"]+)"`)
+	protoBodyRegex         = regexp.MustCompile(`\bbody\s*:\s*"([^"]*)"`)
+	protoResponseBodyRegex = regexp.MustCompile(`\bresponse_body\s*:\s*"([^"]*)"`)
+	protoPathVarRegex      = regexp.MustCompile(`\{([\w.]+)(?:=[^}]*)?\}`)
+
+	protoStatementReplacer = strings.NewReplacer("{", "{\n", "}", "\n}\n", ";", ";\n")
+)
+
+// ProtoDetector lists the RPCs of the gRPC services declared in .proto files
+type ProtoDetector struct{}
+
+// NewProtoDetector creates a detector for .proto files
+func NewProtoDetector() *ProtoDetector {
+	return &ProtoDetector{}
+}
+
+// Name implements detector.Detector
+func (d *ProtoDetector) Name() string {
+	return "proto"
+}
+
+// Supports reports whether path is a protobuf definition
+func (d *ProtoDetector) Supports(path string) bool {
+	return strings.ToLower(filepath.Ext(path)) == ".proto"
+}
+
+// Detect returns an endpoint for every RPC, with request and response
+// messages resolved across the files of the directory
+func (d *ProtoDetector) Detect(files []detector.File) ([]*models.Endpoint, error) {
+	messages := make(map[string]*models.Schema)
+	for _, file := range files {
+		for name, schema := range protoMessages(strings.Split(string(file.Content), "\n")) {
+			messages[name] = schema
+		}
+	}
+
+	var endpoints []*models.Endpoint
+	for _, file := range files {
+		endpoints = append(endpoints, protoRPCs(file, messages)...)
+	}
+	return endpoints, nil
+}
+
+// protoRPCs parses the services of a .proto file. RPCs with
+// google.api.http options also yield the REST endpoints grpc-gateway
+// generates for them.
+func protoRPCs(file detector.File, messages map[string]*models.Schema) []*models.Endpoint {
+	var endpoints []*models.Endpoint
+	lines := strings.Split(string(file.Content), "\n")
+	pkg, service := "", ""
+
+	for i, line := range lines {
+		if matches := protoPackageRegex.FindStringSubmatch(line); matches != nil {
+			pkg = matches[1]
+			continue
+		}
+		if matches := protoServiceRegex.FindStringSubmatch(line); matches != nil {
+			service = matches[1]
+			continue
+		}
+		matches := protoRPCRegex.FindStringSubmatch(line)
+		if matches == nil || service == "" {
+			continue
+		}
+
+		qualified := service
+		if pkg != "" {
+			qualified = pkg + "." + service
+		}
+
+		// The RPC ends with ";" or with the brace closing its options
+		end := i
+		for depth := protoBraces(line); depth > 0 && end+1 < len(lines); {
+			end++
+			depth += protoBraces(lines[end])
+		}
+
+		rpc := &models.Endpoint{
+			Kind:      models.KindGRPC,
+			Method:    "RPC",
+			Path:      "/" + qualified + "/" + matches[1],
+			File:      file.Path,
+			Line:      i + 1,
+			Function:  matches[1],
+			Tags:      []string{service},
+			Language:  "Protobuf",
+			Framework: "gRPC",
+			RawCode:   strings.Join(lines[leadingLines(lines, i):end+1], "\n"),
+			Request:   protoSchema(matches[2], matches[3], messages),
+			Response:  protoSchema(matches[4], matches[5], messages),
+		}
+		for _, segment := range strings.Split(pkg, ".") {
+			if pathVersionRegex.MatchString(segment) {
+				rpc.Version = NormalizeVersion(segment)
+			}
+		}
+		if summary := firstSentence(docComment(lines[leadingLines(lines, i):i]), matches[1]); summary != "" {
+			rpc.Summary = summary
+			rpc.SummarySource = models.SummaryFromDocs
+		}
+
+		endpoints = append(endpoints, rpc)
+		endpoints = append(endpoints, gatewayEndpoints(rpc, lines, i, end, matches[3], matches[5], messages)...)
+	}
+
+	return endpoints
+}
+
+// gatewayEndpoints returns the REST endpoints declared by the
+// google.api.http option of the RPC spanning lines start to end, including
+// its additional_bindings. The request message fields bound neither to the
+// path nor to the body become query parameters, as in grpc-gateway.
+func gatewayEndpoints(rpc *models.Endpoint, lines []string, start, end int, request, response string, messages map[string]*models.Schema) []*models.Endpoint {
+	text := strings.Join(lines[start:end+1], "\n")
+	loc := protoHTTPOptionRegex.FindStringIndex(text)
+	if loc == nil {
+		return nil
+	}
+	option := braceBlock(text, loc[1]-1)
+	offset := loc[1]
+
+	// Each binding is the option itself or one of its additional_bindings
+	type binding struct {
+		text   string
+		offset int
+	}
+	bindings := []binding{{text: option, offset: offset}}
+	main := option
+	for _, extra := range protoAdditionalRegex.FindAllStringIndex(option, -1) {
+		inner := braceBlock(option, extra[1]-1)
+		bindings = append(bindings, binding{text: inner, offset: offset + extra[1]})
+		main = strings.Replace(main, inner, "", 1)
+	}
+	bindings[0].text = main
+
+	requestFields := protoFields(request, messages)
+	var endpoints []*models.Endpoint
+	for _, b := range bindings {
+		method, path, at := "", "", -1
+		if matches := protoBindingRegex.FindStringSubmatchIndex(b.text); matches != nil {
+			method, path, at = strings.ToUpper(b.text[matches[2]:matches[3]]), b.text[matches[4]:matches[5]], matches[0]
+		} else if matches := protoCustomRegex.FindStringSubmatchIndex(b.text); matches != nil {
+			method, path, at = strings.ToUpper(b.text[matches[2]:matches[3]]), b.text[matches[4]:matches[5]], matches[0]
+		}
+		if path == "" {
+			continue
+		}
+
+		endpoint := &models.Endpoint{
+			Kind:          models.KindREST,
+			Method:        method,
+			Path:          path,
+			File:          rpc.File,
+			Line:          start + strings.Count(text[:b.offset+at], "\n") + 1,
+			Function:      rpc.Function,
+			Summary:       rpc.Summary,
+			SummarySource: rpc.SummarySource,
+			Tags:          rpc.Tags,
+			Language:      rpc.Language,
+			Framework:     "grpc-gateway",
+			Version:       pathVersion(path),
+			RawCode:       rpc.RawCode,
+			Response:      rpc.Response,
+		}
+		if endpoint.Version == "" {
+			endpoint.Version = rpc.Version
+		}
+
+		bound := make(map[string]bool)
+		for _, variable := range protoPathVarRegex.FindAllStringSubmatch(path, -1) {
+			name := strings.Split(variable[1], ".")[0]
+			bound[name] = true
+			endpoint.Parameters = addParameter(endpoint.Parameters, models.Parameter{
+				Name:     variable[1],
+				In:       models.ParamPath,
+				Type:     protoFieldPathType(requestFields, variable[1], messages),
+				Required: true,
+			})
+		}
+
+		body := ""
+		if matches := protoBodyRegex.FindStringSubmatch(b.text); matches != nil {
+			body = matches[1]
+		}
+		switch body {
+		case "":
+		case "*":
+			endpoint.Request = rpc.Request
+		default:
+			bound[body] = true
+			bodyType := protoFieldType(requestFields, body)
+			if bodyType == "" {
+				bodyType = body
+			}
+			endpoint.Request = protoSchema("", bodyType, messages)
+		}
+		if matches := protoResponseBodyRegex.FindStringSubmatch(b.text); matches != nil && matches[1] != "*" {
+			endpoint.Response = protoSchema("", protoFieldType(protoFields(response, messages), matches[1]), messages)
+		}
+
+		if body != "*" {
+			for _, field := range requestFields {
+				if !bound[field.Name] {
+					endpoint.Parameters = addParameter(endpoint.Parameters, models.Parameter{
+						Name: field.Name,
+						In:   models.ParamQuery,
+						Type: field.Type,
+					})
+				}
+			}
+		}
+
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints
+}
+
+// protoFields returns the fields of a message, if declared
+func protoFields(name string, messages map[string]*models.Schema) []models.Field {
+	if schema, ok := messages[name[strings.LastIndex(name, ".")+1:]]; ok {
+		return schema.Fields
+	}
+	return nil
+}
+
+// protoFieldType returns the type of the named field, or "" if unknown
+func protoFieldType(fields []models.Field, name string) string {
+	for _, field := range fields {
+		if field.Name == name {
+			return field.Type
+		}
+	}
+	return ""
+}
+
+// protoFieldPathType returns the type of a nested field such as book.name
+func protoFieldPathType(fields []models.Field, path string, messages map[string]*models.Schema) string {
+	names := strings.Split(path, ".")
+	fieldType := protoFieldType(fields, names[0])
+	for _, name := range names[1:] {
+		fieldType = protoFieldType(protoFields(fieldType, messages), name)
+	}
+	return fieldType
+}
+
+// protoBraces returns how many braces a line opens, ignoring comments and
+// braces inside strings such as path templates
+func protoBraces(line string) int {
+	depth := 0
+	inString := false
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '/' && i+1 < len(line) && line[i+1] == '/':
+			return depth
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+		}
+	}
+	return depth
+}
+
+// braceBlock returns the text inside the braces opened at open, ignoring
+// braces inside strings
+func braceBlock(text string, open int) string {
+	depth := 0
+	inString := false
+	for i := open; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return text[open+1 : i]
+			}
+		}
+	}
+	return text[open+1:]
+}
+
+// protoSchema describes an RPC's request or response message, marking
+// streamed messages
+func protoSchema(stream, name string, messages map[string]*models.Schema) *models.Schema {
+	schema := &models.Schema{Name: name}
+	if known, ok := messages[name[strings.LastIndex(name, ".")+1:]]; ok {
+		schema.Fields = known.Fields
+	}
+	if stream != "" {
+		schema.Name = "stream " + name
+	}
+	return schema
+}
+
+// protoMessages parses the top-level fields of the messages declared in a
+// .proto file
+func protoMessages(lines []string) map[string]*models.Schema {
+	messages := make(map[string]*models.Schema)
+	var current *models.Schema
+	depth := 0
+
+	// Put every declaration on its own line, so that one-line messages
+	// such as message Empty {} parse like the others
+	var statements []string
+	for _, line := range lines {
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = line[:idx]
+		}
+		statements = append(statements, strings.Split(protoStatementReplacer.Replace(line), "\n")...)
+	}
+
+	for _, line := range statements {
+		if current == nil {
+			if matches := protoMessageRegex.FindStringSubmatch(line); matches != nil {
+				current = &models.Schema{Name: matches[1]}
+				messages[matches[1]] = current
+				depth = strings.Count(line, "{") - strings.Count(line, "}")
+			}
+			continue
+		}
+
+		if depth == 1 {
+			if matches := protoFieldRegex.FindStringSubmatch(line); matches != nil {
+				fieldType := strings.ReplaceAll(matches[2], " ", "")
+				if strings.TrimSpace(matches[1]) == "repeated" {
+					fieldType = "repeated " + fieldType
+				}
+				current.Fields = append(current.Fields, models.Field{
+					Name:     matches[3],
+					Type:     fieldType,
+					Required: strings.TrimSpace(matches[1]) == "required",
+				})
+			}
+		}
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		if depth <= 0 {
+			current = nil
+		}
+	}
+
+	return messages
+}
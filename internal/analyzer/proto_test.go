@@ -0,0 +1,203 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/tarantino19/restgo/pkg/detector"
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+const libraryProto = `syntax = "proto3";
+
+package library.v1;
+
+import "google/api/annotations.proto";
+
+service LibraryService {
+  // GetBook returns a book by name.
+  rpc GetBook(GetBookRequest) returns (Book) {
+    option (google.api.http) = {
+      get: "/v1/{name=shelves/*/books/*}"
+      additional_bindings {
+        get: "/v1/books/{name}"
+      }
+    };
+  }
+
+  rpc CreateBook(CreateBookRequest) returns (Book) {
+    option (google.api.http) = {
+      post: "/v1/{parent=shelves/*}/books"
+      body: "book"
+      additional_bindings { post: "/v1/books" body: "*" }
+    };
+  }
+
+  rpc WatchBooks(ListBooksRequest) returns (stream Book);
+}
+
+message Book {
+  string name = 1;
+  string title = 2;
+}
+
+message GetBookRequest {
+  string name = 1;
+  bool include_reviews = 2;
+}
+
+message CreateBookRequest {
+  string parent = 1;
+  Book book = 2;
+  string request_id = 3;
+}
+
+message ListBooksRequest {}
+`
+
+func TestProtoDetector(t *testing.T) {
+	endpoints, err := NewProtoDetector().Detect([]detector.File{
+		{Path: "/repo/proto/library.proto", Content: []byte(libraryProto)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byRoute := make(map[string]*models.Endpoint)
+	for _, endpoint := range endpoints {
+		byRoute[endpoint.Method+" "+endpoint.Path] = endpoint
+	}
+	if len(endpoints) != 7 || len(byRoute) != 7 {
+		for _, endpoint := range endpoints {
+			t.Logf("%s %s (line %d)", endpoint.Method, endpoint.Path, endpoint.Line)
+		}
+		t.Fatalf("got %d endpoints, want 3 RPCs and 4 gateway routes", len(endpoints))
+	}
+
+	rpc := byRoute["RPC /library.v1.LibraryService/GetBook"]
+	if rpc == nil {
+		t.Fatal("GetBook RPC not detected")
+	}
+	if rpc.Kind != models.KindGRPC || rpc.Version != "v1" || rpc.Summary != "Returns a book by name." {
+		t.Errorf("GetBook RPC = %+v", rpc)
+	}
+	if watch := byRoute["RPC /library.v1.LibraryService/WatchBooks"]; watch == nil || watch.Response.Name != "stream Book" {
+		t.Errorf("WatchBooks RPC = %+v", watch)
+	}
+
+	tests := []struct {
+		route   string
+		line    int
+		request string   // Name of the request body schema, if any
+		params  []string // Parameters as in:name
+	}{
+		{
+			route:  "GET /v1/{name=shelves/*/books/*}",
+			line:   11,
+			params: []string{"path:name", "query:include_reviews"},
+		},
+		{
+			route:  "GET /v1/books/{name}",
+			line:   13,
+			params: []string{"path:name", "query:include_reviews"},
+		},
+		{
+			route:   "POST /v1/{parent=shelves/*}/books",
+			line:    20,
+			request: "Book",
+			params:  []string{"path:parent", "query:request_id"},
+		},
+		{
+			route:   "POST /v1/books",
+			line:    22,
+			request: "CreateBookRequest",
+		},
+	}
+
+	for _, tt := range tests {
+		endpoint := byRoute[tt.route]
+		if endpoint == nil {
+			t.Errorf("%s not detected", tt.route)
+			continue
+		}
+		if endpoint.Kind != models.KindREST || endpoint.Framework != "grpc-gateway" {
+			t.Errorf("%s: kind %s, framework %s", tt.route, endpoint.Kind, endpoint.Framework)
+		}
+		if endpoint.Line != tt.line {
+			t.Errorf("%s: line %d, want %d", tt.route, endpoint.Line, tt.line)
+		}
+		if endpoint.Response == nil || endpoint.Response.Name != "Book" {
+			t.Errorf("%s: response %+v, want Book", tt.route, endpoint.Response)
+		}
+
+		request := ""
+		if endpoint.Request != nil {
+			request = endpoint.Request.Name
+			if len(endpoint.Request.Fields) == 0 {
+				t.Errorf("%s: request %s has no fields", tt.route, request)
+			}
+		}
+		if request != tt.request {
+			t.Errorf("%s: request %q, want %q", tt.route, request, tt.request)
+		}
+
+		var params []string
+		for _, param := range endpoint.Parameters {
+			params = append(params, param.In+":"+param.Name)
+		}
+		if len(params) != len(tt.params) {
+			t.Errorf("%s: parameters %v, want %v", tt.route, params, tt.params)
+			continue
+		}
+		for i := range params {
+			if params[i] != tt.params[i] {
+				t.Errorf("%s: parameters %v, want %v", tt.route, params, tt.params)
+				break
+			}
+		}
+	}
+}
+
+func TestProtoMessages(t *testing.T) {
+	messages := protoMessages([]string{
+		"message Page { int32 size = 1; string token = 2; }",
+		"message Query {",
+		"  required string q = 1; // Search terms",
+		"  repeated string tags = 2;",
+		"  map<string, string> labels = 3;",
+		"  message Nested {",
+		"    string ignored = 1;",
+		"  }",
+		"}",
+		"message Empty {}",
+	})
+
+	tests := []struct {
+		message string
+		fields  []models.Field
+	}{
+		{"Page", []models.Field{{Name: "size", Type: "int32"}, {Name: "token", Type: "string"}}},
+		{"Query", []models.Field{
+			{Name: "q", Type: "string", Required: true},
+			{Name: "tags", Type: "repeated string"},
+			{Name: "labels", Type: "map<string,string>"},
+		}},
+		{"Empty", nil},
+	}
+
+	for _, tt := range tests {
+		schema, ok := messages[tt.message]
+		if !ok {
+			t.Errorf("message %s not parsed", tt.message)
+			continue
+		}
+		if len(schema.Fields) != len(tt.fields) {
+			t.Errorf("%s fields = %+v, want %+v", tt.message, schema.Fields, tt.fields)
+			continue
+		}
+		for i, field := range schema.Fields {
+			if field != tt.fields[i] {
+				t.Errorf("%s field %d = %+v, want %+v", tt.message, i, field, tt.fields[i])
+			}
+		}
+	}
+}
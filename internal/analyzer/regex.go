@@ -0,0 +1,149 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/tarantino19/restgo/pkg/detector"
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+// RegexDetector finds endpoints by matching framework patterns line by line
+type RegexDetector struct {
+	patterns []FrameworkPatterns
+}
+
+// NewRegexDetector creates a detector for the given framework patterns
+func NewRegexDetector(patterns []FrameworkPatterns) *RegexDetector {
+	return &RegexDetector{patterns: patterns}
+}
+
+// Name implements detector.Detector
+func (d *RegexDetector) Name() string {
+	return "regex"
+}
+
+// Supports reports whether any framework handles files with the path's extension
+func (d *RegexDetector) Supports(path string) bool {
+	ext := filepath.Ext(path)
+	for _, framework := range d.patterns {
+		if matchesExtension(framework, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Detect analyzes each file for endpoints of the frameworks it uses and
+// enriches them with what their handlers reveal
+func (d *RegexDetector) Detect(files []detector.File) ([]*models.Endpoint, error) {
+	var endpoints []*models.Endpoint
+	pkg := newSourcePackage(files)
+
+	for _, file := range files {
+		for _, endpoint := range d.analyzeFile(file, pkg) {
+			enrichEndpoint(endpoint, pkg)
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+
+	return endpoints, nil
+}
+
+// analyzeFile analyzes a single file for endpoints of the frameworks it uses,
+// resolving paths built from constants against the package
+func (d *RegexDetector) analyzeFile(file detector.File, pkg *sourcePackage) []*models.Endpoint {
+	var endpoints []*models.Endpoint
+	lines := strings.Split(string(file.Content), "\n")
+
+	// Patterns shared by several frameworks run once, for the first of them
+	applied := make(map[*regexp.Regexp]bool)
+
+	for _, framework := range detectFrameworks(file.Path, file.Content, d.patterns, file.Dependencies) {
+		var patterns []Pattern
+		for _, pattern := range framework.Patterns {
+			if !applied[pattern.Regex] {
+				applied[pattern.Regex] = true
+				patterns = append(patterns, pattern)
+			}
+		}
+
+		for lineNum, line := range lines {
+			for _, pattern := range patterns {
+				matches := pattern.Regex.FindStringSubmatch(line)
+				if matches != nil {
+					endpoint := extractEndpoint(matches, pattern, file.Path, lineNum+1, lines, framework.Name)
+					if endpoint != nil {
+						endpoint.Path = pkg.resolveRoutePath(file.Path, endpoint.Path, pattern.PathExpression)
+						endpoint.FrameworkVersion = framework.Version
+						endpoints = append(endpoints, endpoint)
+					}
+				}
+			}
+		}
+	}
+
+	return endpoints
+}
+
+// extractEndpoint extracts endpoint information from regex matches
+func extractEndpoint(matches []string, pattern Pattern, filePath string, lineNum int, lines []string, framework string) *models.Endpoint {
+	var method, path, function string
+
+	// Extract method and path based on pattern configuration
+	if pattern.MethodIndex > 0 && pattern.MethodIndex < len(matches) {
+		method = strings.ToUpper(matches[pattern.MethodIndex])
+	}
+
+	if pattern.PathIndex > 0 && pattern.PathIndex < len(matches) {
+		path = matches[pattern.PathIndex]
+	}
+
+	if pattern.FunctionIndex > 0 && pattern.FunctionIndex < len(matches) {
+		function = matches[pattern.FunctionIndex]
+	}
+
+	if path == "" {
+		path = pattern.DefaultPath
+	}
+
+	// Handle special cases
+	if framework == "Rails" && pattern.MethodIndex == 0 && strings.Contains(lines[lineNum-1], "resources") {
+		// Rails resources generates multiple endpoints
+		return &models.Endpoint{
+			Method:    "RESOURCE",
+			Path:      "/" + path,
+			File:      filePath,
+			Line:      lineNum,
+			Framework: framework,
+			Language:  getLanguageFromExtension(filepath.Ext(filePath)),
+			RawCode:   extractCodeContext(lines, lineNum-1, 3),
+		}
+	}
+
+	// Fall back to the pattern's default method, or GET (e.g., Flask without methods specified)
+	if method == "" && path != "" {
+		method = pattern.DefaultMethod
+		if method == "" {
+			method = "GET"
+		}
+	}
+
+	// Skip if we couldn't extract both method and path
+	if method == "" || path == "" {
+		return nil
+	}
+
+	return &models.Endpoint{
+		Kind:      pattern.Kind,
+		Method:    method,
+		Path:      path,
+		File:      filePath,
+		Line:      lineNum,
+		Function:  function,
+		Framework: framework,
+		Language:  getLanguageFromExtension(filepath.Ext(filePath)),
+		RawCode:   extractCodeContext(lines, lineNum-1, 5),
+	}
+}
@@ -0,0 +1,470 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/tarantino19/restgo/pkg/detector"
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+var (
+	goStructRegex       = regexp.MustCompile(`^\s*type\s+(\w+)\s+struct\s*\{`)
+	goFieldRegex        = regexp.MustCompile(`^\s*(\w+)\s+([^\s` + "`" + `]+)\s*(?:` + "`" + `([^` + "`" + `]*)` + "`" + `)?`)
+	goTagRegex          = regexp.MustCompile(`(\w+):"([^"]*)"`)
+	pydanticRegex       = regexp.MustCompile(`^(\s*)class\s+(\w+)\s*\(([^)]*)\)\s*:`)
+	pythonFieldRegex    = regexp.MustCompile(`^\s*(\w+)\s*:\s*([^=#]+?)\s*(?:=\s*(.+?))?\s*(?:#.*)?$`)
+	javaClassRegex      = regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|static|final|abstract|sealed|non-sealed|partial|readonly)\s+)*(?:class|record)\s+(\w+)\s*(\(([^)]*)\))?`)
+	javaFieldRegex      = regexp.MustCompile(`^\s*(?:(?:private|public|protected|final)\s+)+([\w<>\[\],.? ]+?)\s+(\w+)\s*(?:=[^;]*)?;`)
+	csPropertyRegex     = regexp.MustCompile(`^\s*public\s+(?:required\s+)?([\w<>\[\],.?]+)\s+(\w+)\s*\{\s*get;`)
+	csJSONNameRegex     = regexp.MustCompile(`\[JsonPropertyName\(\s*"([^"]+)"\s*\)\]`)
+	javaAnnotationRegex = regexp.MustCompile(`@\w+(?:\([^)]*\))?\s*`)
+	requiredAnnRegex    = regexp.MustCompile(`@(?:NotNull|NotBlank|NotEmpty)\b|\[Required\]`)
+)
+
+// declaredTypes returns the data types declared in a file, keyed by name
+func declaredTypes(src *sourceFile) map[string]*models.Schema {
+	switch src.language {
+	case "Go":
+		return goStructs(src.lines)
+	case "Python":
+		return pydanticModels(src.lines)
+	case "Java":
+		return javaClasses(src.lines)
+	case "C#":
+		return csharpClasses(src.lines)
+	}
+	return nil
+}
+
+// goStructs parses struct declarations, naming fields after their json tags
+func goStructs(lines []string) map[string]*models.Schema {
+	types := make(map[string]*models.Schema)
+
+	for i, line := range lines {
+		matches := goStructRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		end, ok := braceBlockEnd(lines, i)
+		if !ok {
+			continue
+		}
+		types[matches[1]] = &models.Schema{Name: matches[1], Fields: goStructFields(lines[i+1 : end])}
+	}
+
+	return types
+}
+
+// goStructFields parses the fields of a struct body
+func goStructFields(lines []string) []models.Field {
+	var fields []models.Field
+
+	for _, line := range lines {
+		matches := goFieldRegex.FindStringSubmatch(line)
+		if matches == nil || strings.HasPrefix(strings.TrimSpace(line), "//") {
+			continue
+		}
+
+		field := models.Field{Name: matches[1], Type: matches[2]}
+		for _, tag := range goTagRegex.FindAllStringSubmatch(matches[3], -1) {
+			switch tag[1] {
+			case "json":
+				name := strings.Split(tag[2], ",")[0]
+				if name == "-" {
+					field.Name = ""
+				} else if name != "" {
+					field.Name = name
+				}
+			case "binding", "validate":
+				if strings.Contains(tag[2], "required") {
+					field.Required = true
+				}
+			}
+		}
+		if field.Name != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// pydanticModels parses classes deriving from BaseModel (or another model in the file)
+func pydanticModels(lines []string) map[string]*models.Schema {
+	types := make(map[string]*models.Schema)
+
+	for i, line := range lines {
+		matches := pydanticRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		base := strings.TrimSpace(matches[3])
+		parent, isModel := types[base]
+		if !isModel && !strings.HasSuffix(base, "BaseModel") {
+			continue
+		}
+
+		schema := &models.Schema{Name: matches[2]}
+		if parent != nil {
+			schema.Fields = append(schema.Fields, parent.Fields...)
+		}
+
+		indent := len(matches[1])
+		for _, fieldLine := range lines[i+1:] {
+			if strings.TrimSpace(fieldLine) == "" {
+				continue
+			}
+			if indentation(fieldLine) <= indent {
+				break
+			}
+			field := pythonFieldRegex.FindStringSubmatch(fieldLine)
+			if field == nil || indentation(fieldLine) > indent+4 {
+				continue
+			}
+			schema.Fields = append(schema.Fields, models.Field{
+				Name:     field[1],
+				Type:     field[2],
+				Required: pythonFieldRequired(field[2], field[3]),
+			})
+		}
+		types[schema.Name] = schema
+	}
+
+	return types
+}
+
+// pythonFieldRequired reports whether a Pydantic field has no default
+func pythonFieldRequired(fieldType, defaultValue string) bool {
+	if strings.HasPrefix(fieldType, "Optional[") || strings.Contains(fieldType, "None") {
+		return false
+	}
+	return defaultValue == "" || strings.HasPrefix(defaultValue, "Field(...") || defaultValue == "..."
+}
+
+// javaClasses parses class fields and record components
+func javaClasses(lines []string) map[string]*models.Schema {
+	types := make(map[string]*models.Schema)
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if isCommentLine(trimmed) || strings.HasPrefix(trimmed, "@") {
+			continue
+		}
+		matches := javaClassRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		schema := &models.Schema{Name: matches[1]}
+		if matches[2] != "" {
+			// record User(String name, int age)
+			for _, component := range splitParams(matches[3]) {
+				parts := strings.Fields(javaAnnotationRegex.ReplaceAllString(component, ""))
+				if len(parts) >= 2 {
+					schema.Fields = append(schema.Fields, models.Field{
+						Name:     parts[len(parts)-1],
+						Type:     strings.Join(parts[:len(parts)-1], " "),
+						Required: requiredAnnRegex.MatchString(component),
+					})
+				}
+			}
+			types[schema.Name] = schema
+			continue
+		}
+
+		end, ok := braceBlockEnd(lines, i)
+		if !ok {
+			continue
+		}
+		for j := i + 1; j < end; j++ {
+			field := javaFieldRegex.FindStringSubmatch(lines[j])
+			if field == nil || strings.Contains(lines[j], " static ") {
+				continue
+			}
+			schema.Fields = append(schema.Fields, models.Field{
+				Name:     field[2],
+				Type:     strings.TrimSpace(field[1]),
+				Required: requiredAnnRegex.MatchString(lines[j-1]) || requiredAnnRegex.MatchString(lines[j]),
+			})
+		}
+		types[schema.Name] = schema
+	}
+
+	return types
+}
+
+// csharpClasses parses auto-properties of C# classes
+func csharpClasses(lines []string) map[string]*models.Schema {
+	types := make(map[string]*models.Schema)
+
+	for i, line := range lines {
+		matches := javaClassRegex.FindStringSubmatch(line)
+		if matches == nil || matches[2] != "" || isCommentLine(strings.TrimSpace(line)) {
+			continue
+		}
+		end, ok := braceBlockEnd(lines, i)
+		if !ok {
+			continue
+		}
+
+		schema := &models.Schema{Name: matches[1]}
+		for j := i + 1; j < end; j++ {
+			property := csPropertyRegex.FindStringSubmatch(lines[j])
+			if property == nil {
+				continue
+			}
+			field := models.Field{
+				Name:     property[2],
+				Type:     property[1],
+				Required: strings.Contains(lines[j], " required ") || requiredAnnRegex.MatchString(lines[j-1]),
+			}
+			if name := csJSONNameRegex.FindStringSubmatch(lines[j-1]); name != nil {
+				field.Name = name[1]
+			}
+			schema.Fields = append(schema.Fields, field)
+		}
+		types[schema.Name] = schema
+	}
+
+	return types
+}
+
+var (
+	goBindRegex          = regexp.MustCompile(`\.(?:ShouldBindJSON|BindJSON|ShouldBind|Bind|ShouldBindWith|BindWith|ShouldBindBodyWith|Decode)\(\s*&(\w+)`)
+	goJSONResponseRegex  = regexp.MustCompile(`\.(?:JSON|IndentedJSON|PureJSON)\(\s*([^,]+),\s*&?(\w+(?:\.\w+)?)\s*(\{)?`)
+	fastAPIResponseRegex = regexp.MustCompile(`response_model\s*=\s*([\w.\[\], ]+?)\s*[,)]`)
+	pythonReturnRegex    = regexp.MustCompile(`\)\s*->\s*([\w.\[\], ]+?)\s*:\s*$`)
+	springBodyRegex      = regexp.MustCompile(`@RequestBody\s+(?:@\w+(?:\([^)]*\))?\s+)*([\w<>\[\],.? ]+?)\s+\w+\s*[,)]`)
+	javaReturnRegex      = regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|final|synchronized)\s+)*([\w<>\[\],.? ]+?)\s+\w+\s*\(`)
+	aspNetBodyRegex      = regexp.MustCompile(`\[FromBody\]\s*([\w<>\[\],.?]+)\s+\w+`)
+	csReturnRegex        = regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|static|async|virtual|override)\s+)*([\w<>\[\],.?]+)\s+\w+\s*\(`)
+)
+
+// genericWrappers are container types unwrapped to find the body type
+var genericWrappers = []string{
+	"ResponseEntity", "Mono", "Flux", "CompletableFuture", "Optional", "HttpEntity",
+	"Task", "ActionResult", "ValueTask",
+}
+
+// extractSchemas fills the request and response schemas of an endpoint
+// from the declaration and body of its handler
+func extractSchemas(endpoint *models.Endpoint, h *handler, pkg *sourcePackage) {
+	body := h.bodyText()
+
+	switch endpoint.Framework {
+	case "Gin", "Echo":
+		if matches := goBindRegex.FindStringSubmatch(body); matches != nil {
+			endpoint.Request = pkg.schemaFor(goVariableType(body, matches[1]))
+		}
+		if matches := successResponse(goJSONResponseRegex.FindAllStringSubmatch(body, -1)); matches != nil {
+			switch {
+			case matches[3] == "{":
+				// Composite literal such as gin.H{...} or Response{...}
+				endpoint.Response = pkg.schemaFor(matches[2])
+			case !strings.Contains(matches[2], "."):
+				endpoint.Response = pkg.schemaFor(goVariableType(body, matches[2]))
+			}
+		}
+
+	case "FastAPI", "Flask":
+		decorator := h.file.lines[endpoint.Line-1]
+		if matches := fastAPIResponseRegex.FindStringSubmatch(decorator); matches != nil {
+			endpoint.Response = pkg.schemaFor(matches[1])
+		} else if matches := pythonReturnRegex.FindStringSubmatch(h.signature); matches != nil {
+			endpoint.Response = pkg.schemaFor(matches[1])
+		}
+		if bodyType := pythonBodyType(h.signature); bodyType != "" {
+			endpoint.Request = pkg.schemaFor(bodyType)
+		}
+
+	case "Spring":
+		if matches := springBodyRegex.FindStringSubmatch(h.signature); matches != nil {
+			endpoint.Request = pkg.schemaFor(matches[1])
+		}
+		if matches := javaReturnRegex.FindStringSubmatch(h.signature); matches != nil {
+			endpoint.Response = pkg.schemaFor(unwrapGeneric(matches[1]))
+		}
+
+	case "ASP.NET":
+		if matches := aspNetBodyRegex.FindStringSubmatch(h.signature); matches != nil {
+			endpoint.Request = pkg.schemaFor(matches[1])
+		}
+		if matches := csReturnRegex.FindStringSubmatch(h.signature); matches != nil {
+			endpoint.Response = pkg.schemaFor(unwrapGeneric(matches[1]))
+		}
+	}
+}
+
+// successResponse picks the response call whose status (first capture group)
+// is a success code, falling back to the first call
+func successResponse(calls [][]string) []string {
+	for _, call := range calls {
+		status := strings.TrimSpace(call[1])
+		if strings.HasPrefix(status, "2") || status == "http.StatusOK" || status == "http.StatusCreated" || status == "http.StatusAccepted" {
+			return call
+		}
+	}
+	if len(calls) > 0 {
+		return calls[0]
+	}
+	return nil
+}
+
+// goVariableType finds the declared type of a local variable in a Go function body
+func goVariableType(body, name string) string {
+	quoted := regexp.QuoteMeta(name)
+	re := regexp.MustCompile(`(?:var\s+` + quoted + `\s+\*?([\w.]+)|\b` + quoted + `\s*:?=\s*&?([\w.]+)\s*\{)`)
+	matches := re.FindStringSubmatch(body)
+	if matches == nil {
+		return ""
+	}
+	if matches[1] != "" {
+		return matches[1]
+	}
+	return matches[2]
+}
+
+// unwrapGeneric strips response wrappers such as ResponseEntity<T> or Task<ActionResult<T>>
+func unwrapGeneric(typeName string) string {
+	typeName = strings.TrimSpace(typeName)
+	for {
+		open := strings.Index(typeName, "<")
+		if open < 0 || !strings.HasSuffix(typeName, ">") {
+			break
+		}
+		outer := typeName[:open]
+		wrapped := false
+		for _, wrapper := range genericWrappers {
+			if outer == wrapper {
+				wrapped = true
+				break
+			}
+		}
+		if !wrapped {
+			break
+		}
+		typeName = strings.TrimSpace(typeName[open+1 : len(typeName)-1])
+	}
+
+	switch typeName {
+	case "void", "Void", "IActionResult", "ActionResult", "Task", "IResult", "HttpResponseMessage", "?":
+		return ""
+	}
+	return typeName
+}
+
+// schemaFor returns a schema for a type name, with fields when the type is
+// declared in the package. Collections such as List[User] or User[] keep
+// their name and expose the element type's fields.
+func (pkg *sourcePackage) schemaFor(typeName string) *models.Schema {
+	typeName = strings.TrimSpace(typeName)
+	if typeName == "" || typeName == "None" {
+		return nil
+	}
+
+	schema := &models.Schema{Name: typeName}
+	if declared, ok := pkg.types[schemaElement(typeName)]; ok {
+		schema.Fields = declared.Fields
+	}
+	return schema
+}
+
+// schemaElement returns the declared type a type name refers to, such as
+// User for List[User], User[], *models.User or Page<User>
+func schemaElement(typeName string) string {
+	element := strings.TrimSuffix(strings.TrimSpace(typeName), "[]")
+	if open := strings.LastIndexAny(element, "[<"); open >= 0 {
+		element = strings.Trim(element[open+1:], "]> ")
+	}
+	element = strings.TrimPrefix(element, "*")
+	if idx := strings.LastIndex(element, "."); idx >= 0 {
+		element = element[idx+1:]
+	}
+	return element
+}
+
+// pythonBodyType returns the annotation of the first parameter of a FastAPI
+// or Flask handler read from the request body: a class-typed parameter
+// without a marker, or one marked with Body()
+func pythonBodyType(signature string) string {
+	for _, raw := range signatureParams(signature) {
+		name, paramType, defaultValue := splitPythonParam(raw)
+		if name == "" || name == "self" || strings.HasPrefix(name, "*") {
+			continue
+		}
+		paramType, marker := unwrapAnnotated(paramType)
+		if marker == "" {
+			marker = defaultValue
+		}
+		switch paramType {
+		case "Request", "Response", "BackgroundTasks", "WebSocket":
+			continue
+		}
+		if call, _ := splitCall(marker); call == "Body" || (call == "" && !isScalarAnnotation(paramType)) {
+			return paramType
+		}
+	}
+	return ""
+}
+
+// packageTypes returns the data types declared in files, keyed by name.
+// The first declaration of a name wins.
+func packageTypes(files []detector.File) map[string]*models.Schema {
+	types := make(map[string]*models.Schema)
+	for _, file := range files {
+		src := &sourceFile{
+			language: getLanguageFromExtension(filepath.Ext(file.Path)),
+			lines:    strings.Split(string(file.Content), "\n"),
+		}
+		for name, schema := range declaredTypes(src) {
+			if _, ok := types[name]; !ok && len(schema.Fields) > 0 {
+				types[name] = schema
+			}
+		}
+	}
+	return types
+}
+
+// resolveSchemas fills in the fields of request and response types declared
+// outside the handler's directory, such as DTOs in a dto package or models
+// in a schemas module, from the types declared by the endpoint's service
+func resolveSchemas(endpoints []*models.Endpoint, types map[string]map[string]*models.Schema) {
+	for _, endpoint := range endpoints {
+		for _, schema := range []*models.Schema{endpoint.Request, endpoint.Response} {
+			if schema == nil || len(schema.Fields) > 0 {
+				continue
+			}
+			if declared, ok := types[endpoint.Service][schemaElement(schema.Name)]; ok {
+				schema.Fields = declared.Fields
+			}
+		}
+	}
+}
+
+// splitParams splits a parameter list on top-level commas
+func splitParams(params string) []string {
+	var parts []string
+	depth := 0
+	start := 0
+
+	for i, c := range params {
+		switch c {
+		case '<', '(', '[':
+			depth++
+		case '>', ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(params[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if rest := strings.TrimSpace(params[start:]); rest != "" {
+		parts = append(parts, rest)
+	}
+
+	return parts
+}
@@ -0,0 +1,305 @@
+package analyzer
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/tarantino19/restgo/pkg/detector"
+	"github.com/tarantino19/restgo/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// samTemplateRegex matches the top-level keys of an AWS SAM or
+// CloudFormation template
+var samTemplateRegex = regexp.MustCompile(`(?m)^(?:Transform:.*AWS::Serverless|Resources:)`)
+
+// lambdaExtensions are the source files a Lambda handler module may live in
+var lambdaExtensions = []string{".js", ".mjs", ".cjs", ".ts", ".py"}
+
+// ServerlessDetector finds the API Gateway routes of Lambda functions
+// declared in serverless.yml (http and httpApi events) and AWS SAM
+// templates (Api and HttpApi events)
+type ServerlessDetector struct {
+	files fileSystem // Where handler modules are read from
+}
+
+// NewServerlessDetector creates a detector for serverless configuration files
+func NewServerlessDetector() *ServerlessDetector {
+	return newServerlessDetector(osFileSystem{})
+}
+
+// newServerlessDetector creates a detector reading handlers from files
+func newServerlessDetector(files fileSystem) *ServerlessDetector {
+	return &ServerlessDetector{files: files}
+}
+
+// Name implements detector.Detector
+func (d *ServerlessDetector) Name() string {
+	return "serverless"
+}
+
+// Supports reports whether path is a Serverless Framework config or a SAM template
+func (d *ServerlessDetector) Supports(path string) bool {
+	switch strings.ToLower(filepath.Base(path)) {
+	case "serverless.yml", "serverless.yaml", "template.yaml", "template.yml", "sam.yaml", "sam.yml":
+		return true
+	}
+	return false
+}
+
+// Detect returns an endpoint for every HTTP event, located at its handler
+// when the handler source is found
+func (d *ServerlessDetector) Detect(files []detector.File) ([]*models.Endpoint, error) {
+	var endpoints []*models.Endpoint
+	var errs []string
+
+	for _, file := range files {
+		if !isServerlessConfig(file) {
+			// A template.yaml of another tool, such as a Go or Helm template
+			continue
+		}
+		var root yaml.Node
+		if err := yaml.Unmarshal(file.Content, &root); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", file.Path, err))
+			continue
+		}
+		doc := &root
+		if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+			doc = doc.Content[0]
+		}
+
+		var routes []lambdaRoute
+		if functions := mappingValue(doc, "functions"); functions != nil {
+			routes = serverlessRoutes(functions)
+		} else if resources := mappingValue(doc, "Resources"); resources != nil {
+			routes = samRoutes(doc, resources)
+		}
+		for _, route := range routes {
+			endpoints = append(endpoints, route.endpoint(d.files, file.Path))
+		}
+	}
+
+	if len(errs) > 0 {
+		return endpoints, fmt.Errorf("invalid serverless configuration: %s", strings.Join(errs, "; "))
+	}
+	return endpoints, nil
+}
+
+// isServerlessConfig reports whether a supported file is a Serverless
+// Framework config or declares the resources of a SAM template
+func isServerlessConfig(file detector.File) bool {
+	switch strings.ToLower(filepath.Base(file.Path)) {
+	case "serverless.yml", "serverless.yaml":
+		return true
+	}
+	return samTemplateRegex.Match(file.Content)
+}
+
+// lambdaRoute is an HTTP event of a Lambda function
+type lambdaRoute struct {
+	framework  string
+	method     string
+	path       string
+	line       int
+	codeDir    string // Directory the handler module path is relative to
+	handler    string // Handler reference such as src/users.get
+	middleware []string
+}
+
+// serverlessRoutes parses the http and httpApi events of serverless.yml functions
+func serverlessRoutes(functions *yaml.Node) []lambdaRoute {
+	var routes []lambdaRoute
+	for i := 0; i+1 < len(functions.Content); i += 2 {
+		function := functions.Content[i+1]
+		handler := scalarValue(mappingValue(function, "handler"))
+		events := mappingValue(function, "events")
+		if events == nil {
+			continue
+		}
+
+		for _, event := range events.Content {
+			for _, key := range []string{"http", "httpApi"} {
+				value := mappingValue(event, key)
+				if value == nil {
+					continue
+				}
+				route := lambdaRoute{framework: "Serverless", handler: handler, line: value.Line}
+
+				if value.Kind == yaml.ScalarNode {
+					// Shorthand such as "GET users/{id}" or "*"
+					fields := strings.Fields(value.Value)
+					switch len(fields) {
+					case 1:
+						route.method, route.path = "ANY", fields[0]
+					case 2:
+						route.method, route.path = fields[0], fields[1]
+					default:
+						continue
+					}
+				} else {
+					route.method = scalarValue(mappingValue(value, "method"))
+					route.path = scalarValue(mappingValue(value, "path"))
+					if authorizer := mappingValue(value, "authorizer"); authorizer != nil {
+						name := scalarValue(authorizer)
+						if name == "" {
+							name = scalarValue(mappingValue(authorizer, "name")) + scalarValue(mappingValue(authorizer, "type"))
+						}
+						route.middleware = append(route.middleware, "authorizer: "+name)
+					}
+					if scalarValue(mappingValue(value, "private")) == "true" {
+						route.middleware = append(route.middleware, "private: api key")
+					}
+				}
+				routes = append(routes, route)
+			}
+		}
+	}
+	return routes
+}
+
+// samRoutes parses the Api and HttpApi events of AWS::Serverless::Function
+// resources, applying the CodeUri and Handler of the Globals section
+func samRoutes(doc, resources *yaml.Node) []lambdaRoute {
+	globalFunction := mappingValue(orEmpty(mappingValue(doc, "Globals")), "Function")
+
+	var routes []lambdaRoute
+	for i := 0; i+1 < len(resources.Content); i += 2 {
+		resource := resources.Content[i+1]
+		if scalarValue(mappingValue(resource, "Type")) != "AWS::Serverless::Function" {
+			continue
+		}
+		props := orEmpty(mappingValue(resource, "Properties"))
+
+		codeDir := scalarValue(mappingValue(props, "CodeUri"))
+		handler := scalarValue(mappingValue(props, "Handler"))
+		if globalFunction != nil {
+			if codeDir == "" {
+				codeDir = scalarValue(mappingValue(globalFunction, "CodeUri"))
+			}
+			if handler == "" {
+				handler = scalarValue(mappingValue(globalFunction, "Handler"))
+			}
+		}
+
+		events := mappingValue(props, "Events")
+		if events == nil {
+			continue
+		}
+		for j := 0; j+1 < len(events.Content); j += 2 {
+			event := events.Content[j+1]
+			switch scalarValue(mappingValue(event, "Type")) {
+			case "Api", "HttpApi":
+			default:
+				continue
+			}
+			eventProps := orEmpty(mappingValue(event, "Properties"))
+			route := lambdaRoute{
+				framework: "AWS SAM",
+				method:    scalarValue(mappingValue(eventProps, "Method")),
+				path:      scalarValue(mappingValue(eventProps, "Path")),
+				line:      events.Content[j].Line,
+				codeDir:   codeDir,
+				handler:   handler,
+			}
+			if route.method == "" {
+				route.method = "ANY"
+			}
+			if route.path == "" {
+				route.path = "/{proxy+}"
+			}
+			if auth := mappingValue(eventProps, "Auth"); auth != nil {
+				if authorizer := scalarValue(mappingValue(auth, "Authorizer")); authorizer != "" {
+					route.middleware = append(route.middleware, "authorizer: "+authorizer)
+				}
+				if scalarValue(mappingValue(auth, "ApiKeyRequired")) == "true" {
+					route.middleware = append(route.middleware, "api key")
+				}
+			}
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// endpoint converts a route declared in the config file at path. When the
+// handler's source is found, the endpoint points at the handler and takes
+// its code, doc comment and parameters.
+func (r lambdaRoute) endpoint(files fileSystem, path string) *models.Endpoint {
+	endpoint := &models.Endpoint{
+		Kind:       models.KindREST,
+		Source:     models.SourceConfig,
+		Method:     strings.ToUpper(r.method),
+		Path:       "/" + strings.TrimPrefix(r.path, "/"),
+		File:       path,
+		Line:       r.line,
+		Function:   r.handler,
+		Language:   "YAML",
+		Framework:  r.framework,
+		Middleware: r.middleware,
+	}
+	if endpoint.Method == "*" {
+		endpoint.Method = "ANY"
+	}
+	if endpoint.Path == "/*" {
+		endpoint.Path = "/{proxy+}"
+	}
+	endpoint.AuthRequired = len(endpoint.Middleware) > 0
+	endpoint.Version = pathVersion(endpoint.Path)
+
+	h := lambdaHandler(files, filepath.Join(filepath.Dir(path), r.codeDir), r.handler)
+	if h == nil {
+		endpoint.RawCode = fmt.Sprintf("%s %s -> %s", endpoint.Method, endpoint.Path, r.handler)
+		extractParameters(endpoint, nil)
+		return endpoint
+	}
+
+	endpoint.File = h.file.path
+	endpoint.Line = h.start + 1
+	endpoint.Function = h.name
+	endpoint.Language = h.file.language
+	endpoint.RawCode = strings.Join(h.file.lines[leadingLines(h.file.lines, h.start):h.end+1], "\n")
+	extractDocSummary(endpoint, h.file, h)
+	extractParameters(endpoint, h)
+	return endpoint
+}
+
+// lambdaHandler locates a handler reference such as src/users.get: the
+// function get in src/users.js, .ts or .py under dir
+func lambdaHandler(files fileSystem, dir, reference string) *handler {
+	idx := strings.LastIndex(reference, ".")
+	if idx <= 0 {
+		return nil
+	}
+	module, name := reference[:idx], reference[idx+1:]
+
+	for _, ext := range lambdaExtensions {
+		path := filepath.Join(dir, filepath.FromSlash(module)+ext)
+		content, err := files.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		pkg := newSourcePackage([]detector.File{{Path: path, Content: content}})
+		if h := declarationNamed(pkg.files[path], name); h != nil {
+			return h
+		}
+	}
+	return nil
+}
+
+// scalarValue returns the value of a scalar YAML node, or ""
+func scalarValue(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+// orEmpty returns node, or an empty mapping when node is nil
+func orEmpty(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return &yaml.Node{Kind: yaml.MappingNode}
+	}
+	return node
+}
@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/tarantino19/restgo/pkg/detector"
+)
+
+const samTemplate = `AWSTemplateFormatVersion: '2010-09-09'
+Transform: AWS::Serverless-2016-10-31
+Resources:
+  GetUser:
+    Type: AWS::Serverless::Function
+    Properties:
+      CodeUri: src/
+      Handler: users.get
+      Events:
+        Api:
+          Type: Api
+          Properties:
+            Path: /users/{id}
+            Method: get
+`
+
+func TestServerlessDetectorTemplates(t *testing.T) {
+	d := newServerlessDetector(rootedFS{root: "/repo", fsys: fstest.MapFS{}})
+
+	tests := []struct {
+		name      string
+		path      string
+		content   string
+		endpoints int
+		err       bool
+	}{
+		{name: "SAM template", path: "/repo/template.yaml", content: samTemplate, endpoints: 1},
+		{
+			name:    "Go template",
+			path:    "/repo/deploy/template.yaml",
+			content: "name: {{ .Name }}\nreplicas: {{ .Replicas }\n  - bad: [\n",
+		},
+		{
+			name:    "other YAML",
+			path:    "/repo/sam.yml",
+			content: "title: Not a template\nitems: [a, b]\n",
+		},
+		{
+			name:    "invalid SAM template",
+			path:    "/repo/infra/template.yml",
+			content: "Transform: AWS::Serverless-2016-10-31\nResources:\n  Fn: [\n",
+			err:     true,
+		},
+		{
+			name:    "invalid serverless.yml",
+			path:    "/repo/serverless.yml",
+			content: "functions:\n  hello: [\n",
+			err:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		if !d.Supports(tt.path) {
+			t.Errorf("%s: %s not supported", tt.name, tt.path)
+			continue
+		}
+		endpoints, err := d.Detect([]detector.File{{Path: tt.path, Content: []byte(tt.content)}})
+		if (err != nil) != tt.err {
+			t.Errorf("%s: error %v, want error %v", tt.name, err, tt.err)
+		}
+		if len(endpoints) != tt.endpoints {
+			t.Errorf("%s: %d endpoints, want %d", tt.name, len(endpoints), tt.endpoints)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/tarantino19/restgo/pkg/detector"
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+// sourceFile is a parsed view of a file shared by the enrichment steps
+type sourceFile struct {
+	path      string
+	language  string
+	lines     []string
+	constants constTable
+}
+
+// sourcePackage holds the files of a directory and the types and constants
+// they declare, so a handler or route can refer to one declared in a sibling
+// file
+type sourcePackage struct {
+	files     map[string]*sourceFile
+	order     []*sourceFile
+	types     map[string]*models.Schema
+	constants constTable
+}
+
+// newSourcePackage parses the files of a directory
+func newSourcePackage(files []detector.File) *sourcePackage {
+	pkg := &sourcePackage{
+		files:     make(map[string]*sourceFile),
+		types:     make(map[string]*models.Schema),
+		constants: make(constTable),
+	}
+
+	for _, file := range files {
+		src := &sourceFile{
+			path:     file.Path,
+			language: getLanguageFromExtension(filepath.Ext(file.Path)),
+			lines:    strings.Split(string(file.Content), "\n"),
+		}
+		pkg.files[file.Path] = src
+		pkg.order = append(pkg.order, src)
+
+		for name, schema := range declaredTypes(src) {
+			pkg.types[name] = schema
+		}
+
+		src.constants = declaredConstants(src)
+		for name, expr := range src.constants {
+			pkg.constants.add(name, expr)
+		}
+	}
+
+	return pkg
+}
+
+// handler is the function that serves an endpoint
+type handler struct {
+	file      *sourceFile
+	name      string
+	start     int    // Index of the declaration line
+	end       int    // Index of the last line of the body
+	signature string // Declaration up to the opening of the body
+}
+
+// body returns the lines of the handler, declaration included
+func (h *handler) body() []string {
+	return h.file.lines[h.start : h.end+1]
+}
+
+// bodyText returns the handler as a single string
+func (h *handler) bodyText() string {
+	return strings.Join(h.body(), "\n")
+}
+
+var (
+	pythonDefRegex    = regexp.MustCompile(`^\s*(?:async\s+)?def\s+(\w+)\s*\(`)
+	methodDeclRegex   = regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|static|async|final|override|virtual|synchronized)\s+)*(?:[\w<>\[\],.?]+(?:\s*<[^()]*>)?\s+)?(\w+)\s*\(`)
+	handlerArgRegex   = regexp.MustCompile(`,\s*(?:\w+\.)*(\w+)\s*\)\s*;?\s*$`)
+	inlineFuncRegex   = regexp.MustCompile(`\bfunc\s*\(|=>|\bfunction\b`)
+	annotationLnRegex = regexp.MustCompile(`^\s*(?:@|\[)`)
+)
+
+// decoratorFrameworks declare routes with decorators, annotations or
+// attributes placed directly above the handler
+var decoratorFrameworks = map[string]bool{
+	"Flask":   true,
+	"FastAPI": true,
+	"Spring":  true,
+	"ASP.NET": true,
+	"NestJS":  true,
+}
+
+// locateHandler finds the function serving an endpoint: the declaration
+// following a decorator, an inline function on the route line, or a
+// function declared by name in the package
+func locateHandler(endpoint *models.Endpoint, src *sourceFile, pkg *sourcePackage) *handler {
+	routeLine := endpoint.Line - 1
+	if routeLine < 0 || routeLine >= len(src.lines) {
+		return nil
+	}
+
+	if decoratorFrameworks[endpoint.Framework] {
+		return declarationAfter(src, routeLine)
+	}
+
+	line := src.lines[routeLine]
+	if inlineFuncRegex.MatchString(line) {
+		if end, ok := braceBlockEnd(src.lines, routeLine); ok {
+			return &handler{file: src, name: endpoint.Function, start: routeLine, end: end, signature: line}
+		}
+	}
+
+	name := endpoint.Function
+	if name == "" {
+		if matches := handlerArgRegex.FindStringSubmatch(line); matches != nil {
+			name = matches[1]
+		}
+	}
+	if name == "" {
+		return nil
+	}
+
+	// Prefer a declaration in the same file, then anywhere in the package
+	if h := declarationNamed(src, name); h != nil {
+		return h
+	}
+	for _, other := range pkg.order {
+		if other != src {
+			if h := declarationNamed(other, name); h != nil {
+				return h
+			}
+		}
+	}
+	return &handler{file: src, name: name, start: routeLine, end: routeLine, signature: line}
+}
+
+// declarationAfter returns the function declared after the decorator at line
+func declarationAfter(src *sourceFile, line int) *handler {
+	for i := line + 1; i < len(src.lines) && i <= line+20; i++ {
+		text := src.lines[i]
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || annotationLnRegex.MatchString(text) || isCommentLine(trimmed) {
+			continue
+		}
+
+		if src.language == "Python" {
+			matches := pythonDefRegex.FindStringSubmatch(text)
+			if matches == nil {
+				// Continuation of a multi-line decorator
+				continue
+			}
+			start, end, signature := pythonBlock(src.lines, i)
+			return &handler{file: src, name: matches[1], start: start, end: end, signature: signature}
+		}
+
+		matches := methodDeclRegex.FindStringSubmatch(text)
+		if matches == nil || isKeyword(matches[1]) {
+			continue
+		}
+		end, ok := braceBlockEnd(src.lines, i)
+		if !ok {
+			return nil
+		}
+		return &handler{file: src, name: matches[1], start: i, end: end, signature: signatureBeforeBody(src.lines, i)}
+	}
+	return nil
+}
+
+// declarationPatterns match the declaration of a named function per language
+var declarationPatterns = map[string]func(name string) *regexp.Regexp{
+	"Go": func(name string) *regexp.Regexp {
+		return regexp.MustCompile(`^\s*func\s+(?:\([^)]*\)\s*)?` + regexp.QuoteMeta(name) + `\s*\(`)
+	},
+	"JavaScript": jsDeclaration,
+	"TypeScript": jsDeclaration,
+	"Python": func(name string) *regexp.Regexp {
+		return regexp.MustCompile(`^\s*(?:async\s+)?def\s+` + regexp.QuoteMeta(name) + `\s*\(`)
+	},
+}
+
+// jsDeclaration matches function declarations, arrow functions, class
+// methods and CommonJS exports
+func jsDeclaration(name string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(name)
+	return regexp.MustCompile(`^\s*(?:export\s+)?(?:(?:async\s+)?function\s*\*?\s*` + quoted + `\s*\(|(?:const|let|var)\s+` + quoted + `\s*=|(?:module\.)?exports\.` + quoted + `\s*=|(?:async\s+)?` + quoted + `\s*\([^)]*\)\s*\{)`)
+}
+
+// declarationRegexes caches the compiled declaration patterns, keyed by
+// language and function name
+var declarationRegexes sync.Map
+
+// declarationNamed finds the declaration of the named function in src
+func declarationNamed(src *sourceFile, name string) *handler {
+	pattern, ok := declarationPatterns[src.language]
+	if !ok {
+		return nil
+	}
+	key := src.language + "\x00" + name
+	cached, ok := declarationRegexes.Load(key)
+	if !ok {
+		cached, _ = declarationRegexes.LoadOrStore(key, pattern(name))
+	}
+	re := cached.(*regexp.Regexp)
+
+	for i, line := range src.lines {
+		if !re.MatchString(line) {
+			continue
+		}
+		if src.language == "Python" {
+			start, end, signature := pythonBlock(src.lines, i)
+			return &handler{file: src, name: name, start: start, end: end, signature: signature}
+		}
+		if end, ok := braceBlockEnd(src.lines, i); ok {
+			return &handler{file: src, name: name, start: i, end: end, signature: signatureBeforeBody(src.lines, i)}
+		}
+	}
+	return nil
+}
+
+// pythonBlock returns the extent of the def starting at line, based on
+// indentation, and its signature up to the trailing colon
+func pythonBlock(lines []string, line int) (int, int, string) {
+	indent := indentation(lines[line])
+
+	// The signature may span several lines
+	sigEnd := line
+	depth := 0
+	for i := line; i < len(lines); i++ {
+		depth += strings.Count(lines[i], "(") - strings.Count(lines[i], ")")
+		sigEnd = i
+		if depth <= 0 && strings.HasSuffix(strings.TrimSpace(stripLineComment(lines[i], "Python")), ":") {
+			break
+		}
+	}
+	signature := strings.Join(lines[line:sigEnd+1], "\n")
+
+	end := sigEnd
+	for i := sigEnd + 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+		if indentation(lines[i]) <= indent {
+			break
+		}
+		end = i
+	}
+
+	return line, end, signature
+}
+
+// signatureBeforeBody returns the declaration starting at line up to its opening brace
+func signatureBeforeBody(lines []string, line int) string {
+	var parts []string
+	for i := line; i < len(lines) && i < line+10; i++ {
+		if idx := strings.Index(lines[i], "{"); idx >= 0 {
+			parts = append(parts, lines[i][:idx])
+			break
+		}
+		parts = append(parts, lines[i])
+	}
+	return strings.Join(parts, "\n")
+}
+
+// braceBlockEnd returns the line on which the first brace block opened at or
+// after line is closed, ignoring braces in strings and line comments
+func braceBlockEnd(lines []string, line int) (int, bool) {
+	depth := 0
+	opened := false
+	var quote rune
+
+	for i := line; i < len(lines); i++ {
+		runes := []rune(lines[i])
+		for j := 0; j < len(runes); j++ {
+			c := runes[j]
+			if quote != 0 {
+				if c == '\\' && quote != '`' {
+					j++
+				} else if c == quote {
+					quote = 0
+				}
+				continue
+			}
+
+			switch c {
+			case '"', '\'', '`':
+				quote = c
+			case '/':
+				if j+1 < len(runes) && runes[j+1] == '/' {
+					j = len(runes)
+				}
+			case '{':
+				depth++
+				opened = true
+			case '}':
+				depth--
+				if opened && depth == 0 {
+					return i, true
+				}
+			}
+		}
+		// Only backtick strings span lines
+		if quote != '`' {
+			quote = 0
+		}
+		// Give up on declarations that never open a body (e.g. interface methods)
+		if !opened && i > line+10 {
+			return 0, false
+		}
+	}
+
+	return 0, false
+}
+
+// indentation returns the width of the leading whitespace of line
+func indentation(line string) int {
+	width := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// isCommentLine reports whether a trimmed line is a comment
+func isCommentLine(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "#") ||
+		strings.HasPrefix(trimmed, "/*") || strings.HasPrefix(trimmed, "*")
+}
+
+// stripLineComment removes a trailing line comment
+func stripLineComment(line, language string) string {
+	marker := "//"
+	if language == "Python" || language == "Ruby" {
+		marker = "#"
+	}
+	if idx := strings.Index(line, marker); idx >= 0 {
+		return line[:idx]
+	}
+	return line
+}
+
+// isKeyword reports whether a word captured as a method name is a control keyword
+func isKeyword(word string) bool {
+	switch word {
+	case "if", "for", "while", "switch", "catch", "return", "new", "class", "using", "foreach", "lock":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package analyzer
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+// statusRule matches an expression that emits a status code. The first
+// capture group holds the code, a status constant or a response helper name.
+type statusRule struct {
+	regex      *regexp.Regexp
+	decorators bool // Match against the decorators of the handler instead of its body
+}
+
+// goStatusCall matches Gin and Echo calls that write a status code
+var goStatusCall = `\.(?:JSON|IndentedJSON|SecureJSON|PureJSON|AsciiJSON|JSONP|JSONPretty|XML|YAML|TOML|ProtoBuf|String|HTML|Data|Blob|Stream|Status|NoContent|Redirect|AbortWithStatus|AbortWithStatusJSON|AbortWithError|WriteHeader)\(\s*(\d{3}|http\.Status\w+)`
+
+// statusRules lists, per framework, the expressions that emit status codes
+var statusRules = map[string][]statusRule{
+	"Gin": {
+		{regex: regexp.MustCompile(goStatusCall)},
+	},
+	"Echo": {
+		{regex: regexp.MustCompile(goStatusCall)},
+		{regex: regexp.MustCompile(`echo\.NewHTTPError\(\s*(\d{3}|http\.Status\w+)`)},
+		{regex: regexp.MustCompile(`echo\.(Err\w+)`)},
+	},
+	"Express": {
+		{regex: regexp.MustCompile(`\.(?:status|sendStatus)\(\s*(\d{3})`)},
+		{regex: regexp.MustCompile(`\bres\.(json|send|render)\(`)},
+		{regex: regexp.MustCompile(`\bres\.(redirect)\(`)},
+	},
+	"NestJS": {
+		{regex: regexp.MustCompile(`@HttpCode\(\s*(\d{3}|HttpStatus\.\w+)`), decorators: true},
+		{regex: regexp.MustCompile(`throw\s+new\s+(\w+)Exception\(`)},
+		{regex: regexp.MustCompile(`new\s+HttpException\([^;]*?,\s*(\d{3}|HttpStatus\.\w+)`)},
+	},
+	"Flask": {
+		{regex: regexp.MustCompile(`(?m)^\s*return\s+.+,\s*(\d{3})\s*$`)},
+		{regex: regexp.MustCompile(`\babort\(\s*(\d{3})`)},
+		{regex: regexp.MustCompile(`make_response\(.*,\s*(\d{3})\s*\)`)},
+		{regex: regexp.MustCompile(`\bredirect\(.*code\s*=\s*(\d{3})`)},
+	},
+	"FastAPI": {
+		{regex: regexp.MustCompile(`status_code\s*=\s*(\d{3}|status\.HTTP_\w+)`), decorators: true},
+		{regex: regexp.MustCompile(`HTTPException\(\s*(?:status_code\s*=\s*)?(\d{3}|status\.HTTP_\w+)`)},
+		{regex: regexp.MustCompile(`Response\([^)]*status_code\s*=\s*(\d{3}|status\.HTTP_\w+)`)},
+	},
+	"Spring": {
+		{regex: regexp.MustCompile(`@ResponseStatus\(\s*(?:(?:code|value)\s*=\s*)?HttpStatus\.(\w+)`), decorators: true},
+		{regex: regexp.MustCompile(`ResponseEntity\s*\.\s*(ok|created|accepted|noContent|badRequest|notFound|unprocessableEntity|internalServerError)\(`)},
+		{regex: regexp.MustCompile(`ResponseEntity\s*\.\s*status\(\s*(\d{3}|HttpStatus\.\w+)`)},
+		{regex: regexp.MustCompile(`new\s+ResponseEntity\s*<[^>]*>\s*\([^;]*HttpStatus\.(\w+)`)},
+		{regex: regexp.MustCompile(`new\s+ResponseStatusException\(\s*HttpStatus\.(\w+)`)},
+	},
+	"ASP.NET": {
+		{regex: regexp.MustCompile(`ProducesResponseType\((?:[^()]|\([^()]*\))*?\b(\d{3}|StatusCodes\.Status\w+)\s*\)`), decorators: true},
+		{regex: regexp.MustCompile(`(?:^|[^.\w])(Ok|Created|CreatedAtAction|CreatedAtRoute|Accepted|AcceptedAtAction|NoContent|BadRequest|Unauthorized|Forbid|NotFound|Conflict|UnprocessableEntity|ValidationProblem|Problem|Redirect)\(`)},
+		{regex: regexp.MustCompile(`StatusCode\(\s*(\d{3}|StatusCodes\.Status\w+)`)},
+	},
+}
+
+// implicitSuccess lists the status code frameworks send when the handler
+// returns without setting one
+var implicitSuccess = map[string]int{
+	"Flask":   200,
+	"FastAPI": 200,
+	"Spring":  200,
+	"ASP.NET": 200,
+	"NestJS":  200,
+}
+
+// statusNames maps status constant and helper names, lowercased and without
+// separators (e.g. StatusNotFound, NOT_FOUND, NotFound()), to their codes
+var statusNames = map[string]int{
+	"ok":                    200,
+	"json":                  200,
+	"send":                  200,
+	"render":                200,
+	"created":               201,
+	"createdataction":       201,
+	"createdatroute":        201,
+	"accepted":              202,
+	"acceptedataction":      202,
+	"nocontent":             204,
+	"movedpermanently":      301,
+	"found":                 302,
+	"redirect":              302,
+	"seeother":              303,
+	"notmodified":           304,
+	"temporaryredirect":     307,
+	"permanentredirect":     308,
+	"badrequest":            400,
+	"validationproblem":     400,
+	"unauthorized":          401,
+	"paymentrequired":       402,
+	"forbidden":             403,
+	"forbid":                403,
+	"notfound":              404,
+	"methodnotallowed":      405,
+	"notacceptable":         406,
+	"requesttimeout":        408,
+	"conflict":              409,
+	"gone":                  410,
+	"preconditionfailed":    412,
+	"payloadtoolarge":       413,
+	"requestentitytoolarge": 413,
+	"unsupportedmediatype":  415,
+	"unprocessableentity":   422,
+	"locked":                423,
+	"toomanyrequests":       429,
+	"internalservererror":   500,
+	"problem":               500,
+	"notimplemented":        501,
+	"badgateway":            502,
+	"serviceunavailable":    503,
+	"gatewaytimeout":        504,
+}
+
+var statusDigitsRegex = regexp.MustCompile(`\d{3}`)
+
+// extractStatusCodes records the status codes a handler can respond with,
+// reading decorators around the route in src and status calls in the handler
+func extractStatusCodes(endpoint *models.Endpoint, src *sourceFile, h *handler) {
+	decorators := annotationBlock(src.lines, endpoint.Line-1)
+	body := h.bodyText()
+
+	seen := make(map[int]bool)
+	for _, rule := range statusRules[endpoint.Framework] {
+		text := body
+		if rule.decorators {
+			text = decorators
+		}
+		for _, matches := range rule.regex.FindAllStringSubmatch(text, -1) {
+			if code := statusCode(matches[1]); code != 0 {
+				seen[code] = true
+			}
+		}
+	}
+
+	if code, ok := implicitSuccess[endpoint.Framework]; ok && !hasSuccess(seen) {
+		if endpoint.Framework == "NestJS" && endpoint.Method == "POST" {
+			code = 201
+		}
+		seen[code] = true
+	}
+
+	var codes []int
+	for code := range seen {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	endpoint.StatusCodes = codes
+}
+
+// hasSuccess reports whether a 2xx or 3xx code was found
+func hasSuccess(codes map[int]bool) bool {
+	for code := range codes {
+		if code >= 200 && code < 400 {
+			return true
+		}
+	}
+	return false
+}
+
+// statusCode resolves a literal code, a constant such as http.StatusNotFound,
+// HttpStatus.NOT_FOUND or status.HTTP_404_NOT_FOUND, or a helper name to a
+// status code, returning 0 when unknown
+func statusCode(token string) int {
+	if digits := statusDigitsRegex.FindString(token); digits != "" {
+		code, _ := strconv.Atoi(digits)
+		return code
+	}
+
+	if idx := strings.LastIndex(token, "."); idx >= 0 {
+		token = token[idx+1:]
+	}
+	name := strings.ToLower(strings.ReplaceAll(token, "_", ""))
+	name = strings.TrimPrefix(name, "status")
+	name = strings.TrimPrefix(name, "err")
+	return statusNames[name]
+}
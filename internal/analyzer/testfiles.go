@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// testDirNames are directories that only hold tests and their fixtures
+var testDirNames = map[string]bool{
+	"test":      true,
+	"tests":     true,
+	"__tests__": true,
+	"__test__":  true,
+	"testdata":  true,
+}
+
+// testProjectRegex matches .NET test projects such as Api.Tests or Api.IntegrationTests
+var testProjectRegex = regexp.MustCompile(`\.\w*Tests?$`)
+
+// testFileRegexes match the names of test files by extension, following the
+// conventions of each language's test runners
+var testFileRegexes = map[string]*regexp.Regexp{
+	".go":   regexp.MustCompile(`_test\.go$`),
+	".js":   regexp.MustCompile(`\.(?:test|spec|e2e-spec)\.js$`),
+	".jsx":  regexp.MustCompile(`\.(?:test|spec)\.jsx$`),
+	".mjs":  regexp.MustCompile(`\.(?:test|spec)\.mjs$`),
+	".cjs":  regexp.MustCompile(`\.(?:test|spec)\.cjs$`),
+	".ts":   regexp.MustCompile(`\.(?:test|spec|e2e-spec)\.ts$`),
+	".tsx":  regexp.MustCompile(`\.(?:test|spec)\.tsx$`),
+	".py":   regexp.MustCompile(`^(?:test_\w*|\w+_test|conftest)\.py$`),
+	".java": regexp.MustCompile(`(?:Tests?|IT|TestCase)\.java$`),
+	".kt":   regexp.MustCompile(`(?:Tests?|IT)\.kt$`),
+	".cs":   regexp.MustCompile(`Tests?\.cs$`),
+	".rb":   regexp.MustCompile(`_(?:spec|test)\.rb$`),
+	".php":  regexp.MustCompile(`Test\.php$`),
+}
+
+// isTestDir reports whether a directory name marks a directory of tests
+func isTestDir(name string) bool {
+	return testDirNames[strings.ToLower(name)] || testProjectRegex.MatchString(name)
+}
+
+// isTestFile reports whether the slash-separated path, relative to the
+// scan root, is a test file or lies in a test directory
+func isTestFile(rel string) bool {
+	if re, ok := testFileRegexes[strings.ToLower(path.Ext(rel))]; ok && re.MatchString(path.Base(rel)) {
+		return true
+	}
+	dirs := strings.Split(path.Dir(rel), "/")
+	for _, dir := range dirs {
+		if dir != "." && isTestDir(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// testSkipReason returns why a test file or directory is skipped when
+// tests are excluded, or "" if the path is not a test
+func testSkipReason(rel string, isDir bool) string {
+	switch {
+	case isDir && isTestDir(path.Base(rel)):
+		return "test directory (use --include-tests to scan it)"
+	case !isDir && isTestFile(rel):
+		return "test file (use --include-tests to scan it)"
+	}
+	return ""
+}
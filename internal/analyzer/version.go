@@ -0,0 +1,110 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+var (
+	pathVersionRegex    = regexp.MustCompile(`(?i)^v\d+(?:\.\d+)*(?:-?(?:alpha|beta)\d*)?$`)
+	dateVersionRegex    = regexp.MustCompile(`^\d{4}-\d{2}(?:-\d{2})?$`)
+	nestVersionRegex    = regexp.MustCompile(`@Version\s*\(\s*(\[[^\]]*\]|['"][^'"]*['"])`)
+	nestControllerRegex = regexp.MustCompile(`@Controller\s*\(\s*\{[^}]*\bversion\s*:\s*(\[[^\]]*\]|['"][^'"]*['"])`)
+	mapToVersionRegex   = regexp.MustCompile(`\bMapToApiVersion\s*\(\s*"([^"]+)"`)
+	apiVersionRegex     = regexp.MustCompile(`\bApiVersion\s*\(\s*"([^"]+)"`)
+	springVersionRegex  = regexp.MustCompile(`Mapping\s*\([^)]*\bversion\s*=\s*"([^"]+)"`)
+	versionLiteralRegex = regexp.MustCompile(`['"]([^'"]+)['"]`)
+)
+
+// detectVersion infers the API version of an endpoint from version
+// annotations on the handler or its class, falling back to a version
+// segment of the path such as /v2 or /2024-06-01. An endpoint served in
+// several versions lists them separated by commas.
+func detectVersion(endpoint *models.Endpoint, src *sourceFile) {
+	if versions := annotatedVersions(endpoint, src); len(versions) > 0 {
+		endpoint.Version = strings.Join(versions, ",")
+		return
+	}
+
+	endpoint.Version = pathVersion(endpoint.Path)
+}
+
+// pathVersion returns the version named by a segment of path, if any
+func pathVersion(path string) string {
+	for _, segment := range strings.Split(path, "/") {
+		if pathVersionRegex.MatchString(segment) || dateVersionRegex.MatchString(segment) {
+			return NormalizeVersion(segment)
+		}
+	}
+	return ""
+}
+
+// annotatedVersions returns the versions declared by framework annotations,
+// with those on the handler taking precedence over those on its class
+func annotatedVersions(endpoint *models.Endpoint, src *sourceFile) []string {
+	line := endpoint.Line - 1
+	block := annotationBlock(src.lines, line)
+	classBlock := ""
+	if classLine := enclosingClass(src, line); classLine >= 0 {
+		classBlock = classAnnotations(src.lines, classLine)
+	}
+
+	var versions []string
+	switch endpoint.Framework {
+	case "NestJS":
+		if matches := nestVersionRegex.FindStringSubmatch(block); matches != nil {
+			versions = versionLiterals(matches[1])
+		} else if matches := nestVersionRegex.FindStringSubmatch(classBlock); matches != nil {
+			versions = versionLiterals(matches[1])
+		} else if matches := nestControllerRegex.FindStringSubmatch(classBlock); matches != nil {
+			versions = versionLiterals(matches[1])
+		}
+	case "ASP.NET":
+		for _, re := range []*regexp.Regexp{mapToVersionRegex, apiVersionRegex} {
+			for _, text := range []string{block, classBlock} {
+				if len(versions) > 0 {
+					break
+				}
+				for _, matches := range re.FindAllStringSubmatch(text, -1) {
+					versions = append(versions, matches[1])
+				}
+			}
+		}
+	case "Spring":
+		if matches := springVersionRegex.FindStringSubmatch(block); matches != nil {
+			versions = []string{matches[1]}
+		} else if matches := springVersionRegex.FindStringSubmatch(classBlock); matches != nil {
+			versions = []string{matches[1]}
+		}
+	}
+
+	for i, version := range versions {
+		versions[i] = NormalizeVersion(version)
+	}
+	return uniqueStrings(versions)
+}
+
+// versionLiterals returns the strings of a literal or an array of literals
+func versionLiterals(text string) []string {
+	var versions []string
+	for _, matches := range versionLiteralRegex.FindAllStringSubmatch(text, -1) {
+		versions = append(versions, matches[1])
+	}
+	return versions
+}
+
+// NormalizeVersion formats a version as v<number>, so that /v1, "1" and
+// "1.0" compare equal. Date versions are kept as they are.
+func NormalizeVersion(version string) string {
+	version = strings.TrimSpace(version)
+	if dateVersionRegex.MatchString(version) {
+		return version
+	}
+	version = strings.TrimPrefix(strings.TrimPrefix(version, "v"), "V")
+	for strings.HasSuffix(version, ".0") {
+		version = strings.TrimSuffix(version, ".0")
+	}
+	return "v" + strings.ToLower(version)
+}
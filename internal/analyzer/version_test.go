@@ -0,0 +1,82 @@
+package analyzer
+
+import "testing"
+
+func TestAnnotatedVersions(t *testing.T) {
+	endpoints := analyzeTree(t, map[string]string{
+		"nest/package.json": `{"dependencies": {"@nestjs/core": "^10.0.0"}}`,
+		"nest/src/users.controller.ts": `import { Controller, Get, Post, Version } from '@nestjs/common';
+
+@Controller('users')
+export class UsersController {
+  @Version('2')
+  @Get()
+  findAll() {
+    return [];
+  }
+
+  @Post()
+  create() {
+    return {};
+  }
+}
+`,
+		"nest/src/items.controller.ts": `import { Controller, Get, Version } from '@nestjs/common';
+
+@Controller({ path: 'items', version: '1' })
+export class ItemsController {
+  @Get()
+  findAll() {
+    return [];
+  }
+
+  @Version(['2', '3'])
+  @Get('latest')
+  latest() {
+    return [];
+  }
+}
+`,
+		"spring/pom.xml": `<project><dependencies><dependency><artifactId>spring-boot-starter-web</artifactId></dependency></dependencies></project>`,
+		"spring/src/main/java/OrderController.java": `package com.example;
+
+@RestController
+public class OrderController {
+    @RequestMapping(value = "/orders", method = RequestMethod.GET, version = "2")
+    public List<Order> list() {
+        return List.of();
+    }
+
+    @PostMapping("/orders")
+    public Order create(@RequestBody Order order) {
+        return order;
+    }
+}
+`,
+	})
+
+	tests := []struct {
+		route   string
+		version string
+	}{
+		// A method version does not spread to the other handlers of the class
+		{"GET /users", "v2"},
+		{"POST /users", ""},
+		{"GET /orders", "v2"},
+		{"POST /orders", ""},
+		// A class version applies unless the handler declares its own
+		{"GET /items", "v1"},
+		{"GET /items/latest", "v2,v3"},
+	}
+
+	for _, tt := range tests {
+		endpoint := endpoints[tt.route]
+		if endpoint == nil {
+			t.Errorf("%s not detected", tt.route)
+			continue
+		}
+		if endpoint.Version != tt.version {
+			t.Errorf("%s: version %q, want %q", tt.route, endpoint.Version, tt.version)
+		}
+	}
+}
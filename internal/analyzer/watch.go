@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// WatchDirs returns the directories a scan of root descends into, so that
+// they can be watched for changes
+func (a *Analyzer) WatchDirs(root string) ([]string, error) {
+	ignore, err := newIgnoreMatcher(a.files, root, a.include, a.exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	err = a.files.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		if a.skipReason(ignore, root, path, true, 0) != "" {
+			return filepath.SkipDir
+		}
+		ignore.enterDir(path)
+		dirs = append(dirs, path)
+		return nil
+	})
+	return dirs, err
+}
+
+// Relevant reports whether a change to path, inside the scan of root, can
+// change the endpoints found: a source file, spec, proxy config, manifest
+// or ignore file
+func (a *Analyzer) Relevant(root, path string) bool {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, ".") {
+		for _, ignoreFile := range ignoreFileNames {
+			if name == ignoreFile {
+				return true
+			}
+		}
+		return false
+	}
+	if _, ok := manifestParsers[name]; ok || serviceMarkers[name] || strings.HasSuffix(name, ".csproj") {
+		return true
+	}
+	if !a.includeTests {
+		if rel, err := filepath.Rel(root, path); err == nil && isTestFile(filepath.ToSlash(rel)) {
+			return false
+		}
+	}
+	return a.supports(path) || isProxyConfig(path)
+}
@@ -1,18 +1,40 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"io/fs"
+	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	GeminiAPIKey string `mapstructure:"gemini_api_key"`
+	GeminiAPIKey string          `mapstructure:"gemini_api_key"`
+	Patterns     []PatternConfig `mapstructure:"patterns"`
 }
 
+// PatternConfig declares a user-defined endpoint pattern, e.g. for an
+// in-house framework. Entries sharing a name with a built-in framework
+// extend it; other names define a new framework.
+type PatternConfig struct {
+	Name          string   `mapstructure:"name"`
+	Extensions    []string `mapstructure:"extensions"`
+	Regex         string   `mapstructure:"regex"`
+	MethodIndex   int      `mapstructure:"method_index"`
+	PathIndex     int      `mapstructure:"path_index"`
+	FunctionIndex int      `mapstructure:"function_index"`
+	DefaultMethod string   `mapstructure:"default_method"`
+}
+
+// projectConfigNames are the project-level config files looked up in the scanned directory
+var projectConfigNames = []string{".restgo.yaml", ".restgo.yml"}
+
 var (
 	cfgFile string
 	cfg     *Config
@@ -100,3 +122,90 @@ func GetAPIKey() string {
 	// Finally check viper directly
 	return viper.GetString("gemini_api_key")
 }
+
+// LoadPatternsFS returns the custom patterns declared in the user config
+// followed by those in the project-level config at the root of fsys, such as
+// the working copy or a git revision, validating each one. dir names the
+// tree in error messages.
+func LoadPatternsFS(fsys fs.FS, dir string) ([]PatternConfig, error) {
+	patterns := append([]PatternConfig{}, GetConfig().Patterns...)
+
+	for _, name := range projectConfigNames {
+		projectFile := filepath.Join(dir, name)
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			continue
+		}
+
+		v := viper.New()
+		v.SetConfigType("yaml")
+		if err := v.ReadConfig(bytes.NewReader(content)); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", projectFile, err)
+		}
+
+		var projectPatterns []PatternConfig
+		if err := v.UnmarshalKey("patterns", &projectPatterns); err != nil {
+			return nil, fmt.Errorf("failed to parse patterns in %s: %w", projectFile, err)
+		}
+		patterns = append(patterns, projectPatterns...)
+		break
+	}
+
+	for i, pattern := range patterns {
+		if err := pattern.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid pattern #%d (%s): %w", i+1, pattern.Name, err)
+		}
+	}
+
+	return patterns, nil
+}
+
+// Validate checks that the pattern compiles and its capture indices exist
+func (p PatternConfig) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(p.Extensions) == 0 {
+		return fmt.Errorf("at least one extension is required")
+	}
+	for _, ext := range p.Extensions {
+		if !strings.HasPrefix(ext, ".") {
+			return fmt.Errorf("extension %q must start with a dot", ext)
+		}
+	}
+
+	re, err := regexp.Compile(p.Regex)
+	if err != nil {
+		return fmt.Errorf("invalid regex: %w", err)
+	}
+
+	groups := re.NumSubexp()
+	if p.PathIndex < 1 || p.PathIndex > groups {
+		return fmt.Errorf("path_index %d must refer to one of the %d capture groups", p.PathIndex, groups)
+	}
+	if p.MethodIndex < 0 || p.MethodIndex > groups {
+		return fmt.Errorf("method_index %d must refer to one of the %d capture groups", p.MethodIndex, groups)
+	}
+	if p.FunctionIndex < 0 || p.FunctionIndex > groups {
+		return fmt.Errorf("function_index %d must refer to one of the %d capture groups", p.FunctionIndex, groups)
+	}
+
+	if p.MethodIndex == 0 && p.DefaultMethod == "" {
+		return fmt.Errorf("either method_index or default_method is required")
+	}
+	if p.DefaultMethod != "" && !isHTTPMethod(p.DefaultMethod) {
+		return fmt.Errorf("default_method %q is not an HTTP method", p.DefaultMethod)
+	}
+
+	return nil
+}
+
+// isHTTPMethod reports whether method is a standard HTTP method
+func isHTTPMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
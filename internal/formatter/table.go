@@ -3,6 +3,7 @@ package formatter
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/fatih/color"
@@ -21,7 +22,96 @@ func FormatEndpointsTable(endpoints []*models.Endpoint) {
 	color.Green("\n🔍 REST API Endpoints Summary\n")
 	fmt.Printf("Found %d endpoints\n\n", len(endpoints))
 
-	// Create table
+	// GraphQL, WebSocket and gRPC endpoints and routes registered in tests
+	// get their own sections
+	var rest, tests []*models.Endpoint
+	others := make(map[string][]*models.Endpoint)
+	for _, endpoint := range endpoints {
+		if endpoint.Test {
+			tests = append(tests, endpoint)
+		} else if endpoint.Kind == models.KindREST || endpoint.Kind == "" {
+			rest = append(rest, endpoint)
+		} else {
+			others[endpoint.Kind] = append(others[endpoint.Kind], endpoint)
+		}
+	}
+
+	if len(rest) > 0 {
+		// Render one table per service when scanning a monorepo
+		groups := GroupByService(rest)
+		if len(groups) == 1 {
+			renderTable(rest)
+		} else {
+			for _, group := range groups {
+				color.Magenta("\n🧩 Service: %s (%d endpoints)\n", serviceLabel(group.Service), len(group.Endpoints))
+				renderTable(group.Endpoints)
+			}
+		}
+
+		// Print grouped by file
+		printGroupedByFile(groups)
+	}
+
+	printKindSection("🔗 GraphQL operations", others[models.KindGraphQL])
+	printKindSection("🔌 WebSocket endpoints", others[models.KindWebSocket])
+	printKindSection("📡 gRPC methods", others[models.KindGRPC])
+	printKindSection("🧪 Routes registered in tests", tests)
+}
+
+// printKindSection lists non-REST endpoints with their arguments, messages
+// and summaries
+func printKindSection(title string, endpoints []*models.Endpoint) {
+	if len(endpoints) == 0 {
+		return
+	}
+
+	color.Green("\n%s (%d):\n", title, len(endpoints))
+	for _, ep := range endpoints {
+		fmt.Printf("  • %s %s%s\n", color.CyanString(ep.Method), ep.Path, formatVersion(ep)+formatTags(ep)+formatSchemas(ep)+formatParameters(ep)+formatMiddleware(ep))
+		if ep.Summary != "" {
+			fmt.Printf("      %s\n", ep.Summary)
+		}
+		if ep.PublicURL != "" && ep.PublicURL != ep.Path {
+			color.HiBlack("      → %s", ep.PublicURL)
+		}
+		color.HiBlack("      %s:%d", shortenPath(ep.File), ep.Line)
+	}
+}
+
+// ServiceGroup holds the endpoints belonging to one service
+type ServiceGroup struct {
+	Service   string
+	Endpoints []*models.Endpoint
+}
+
+// GroupByService groups endpoints by service, in order of first appearance
+func GroupByService(endpoints []*models.Endpoint) []ServiceGroup {
+	var groups []ServiceGroup
+	index := make(map[string]int)
+
+	for _, endpoint := range endpoints {
+		i, ok := index[endpoint.Service]
+		if !ok {
+			i = len(groups)
+			index[endpoint.Service] = i
+			groups = append(groups, ServiceGroup{Service: endpoint.Service})
+		}
+		groups[i].Endpoints = append(groups[i].Endpoints, endpoint)
+	}
+
+	return groups
+}
+
+// serviceLabel returns a display name for a service
+func serviceLabel(service string) string {
+	if service == "" {
+		return "(no service)"
+	}
+	return service
+}
+
+// renderTable prints endpoints as a table
+func renderTable(endpoints []*models.Endpoint) {
 	table := tablewriter.NewWriter(os.Stdout)
 
 	// Add header
@@ -29,6 +119,7 @@ func FormatEndpointsTable(endpoints []*models.Endpoint) {
 		color.CyanString("Method"),
 		color.CyanString("Path"),
 		color.CyanString("File"),
+		color.CyanString("Auth"),
 		color.CyanString("Summary"),
 	})
 
@@ -36,17 +127,31 @@ func FormatEndpointsTable(endpoints []*models.Endpoint) {
 	for _, endpoint := range endpoints {
 		method := colorizeMethodSimple(endpoint.Method)
 		path := endpoint.Path
+		if endpoint.PublicURL != "" && endpoint.PublicURL != endpoint.Path {
+			path += "\n" + color.HiBlackString("→ %s", endpoint.PublicURL)
+		}
 		file := fmt.Sprintf("%s:%d", shortenPath(endpoint.File), endpoint.Line)
+		if endpoint.Source == models.SourceSpec || endpoint.Source == models.SourceConfig {
+			file += color.HiBlackString(" (%s)", endpoint.Source)
+		}
 		summary := endpoint.Summary
+		if endpoint.SummarySource == models.SummaryFromDocs {
+			summary += color.HiBlackString(" (docs)")
+		}
 
-		table.Append([]string{method, path, file, summary})
+		table.Append([]string{method, path, file, formatAuth(endpoint), summary})
 	}
 
 	// Render table
 	table.Render()
+}
 
-	// Print grouped by file
-	printGroupedByFile(endpoints)
+// formatAuth shows whether an endpoint is protected by authentication
+func formatAuth(endpoint *models.Endpoint) string {
+	if endpoint.AuthRequired {
+		return color.GreenString("yes")
+	}
+	return color.RedString("no")
 }
 
 // shortenPath shortens file path for display
@@ -58,23 +163,141 @@ func shortenPath(path string) string {
 	return path
 }
 
-// printGroupedByFile prints endpoints grouped by file
-func printGroupedByFile(endpoints []*models.Endpoint) {
+// printGroupedByFile prints endpoints grouped by service and file
+func printGroupedByFile(groups []ServiceGroup) {
 	color.Green("\n📁 Endpoints by File:\n")
 
-	// Group by file
-	fileMap := make(map[string][]*models.Endpoint)
-	for _, endpoint := range endpoints {
-		fileMap[endpoint.File] = append(fileMap[endpoint.File], endpoint)
+	for _, group := range groups {
+		if len(groups) > 1 {
+			color.Magenta("  🧩 %s\n", serviceLabel(group.Service))
+		}
+
+		// Group by file, keeping the order in which files were found
+		var files []string
+		fileMap := make(map[string][]*models.Endpoint)
+		for _, endpoint := range group.Endpoints {
+			if _, ok := fileMap[endpoint.File]; !ok {
+				files = append(files, endpoint.File)
+			}
+			fileMap[endpoint.File] = append(fileMap[endpoint.File], endpoint)
+		}
+
+		// Print each file's endpoints
+		for _, file := range files {
+			eps := fileMap[file]
+			color.Cyan("  %s (%d endpoints)\n", file, len(eps))
+			for _, ep := range eps {
+				fmt.Printf("    • %s %s%s\n", colorizeMethodSimple(ep.Method), ep.Path, formatVersion(ep)+formatTags(ep)+formatSchemas(ep)+formatParameters(ep)+formatStatusCodes(ep)+formatMiddleware(ep))
+				printAPIDocs(ep)
+			}
+		}
 	}
+}
 
-	// Print each file's endpoints
-	for file, eps := range fileMap {
-		color.Cyan("  %s (%d endpoints)\n", file, len(eps))
-		for _, ep := range eps {
-			fmt.Printf("    • %s %s\n", colorizeMethodSimple(ep.Method), ep.Path)
+// formatVersion shows the API version of an endpoint when its path does not
+func formatVersion(endpoint *models.Endpoint) string {
+	if endpoint.Version == "" || strings.Contains(strings.ToLower(endpoint.Path), endpoint.Version) {
+		return ""
+	}
+	return color.CyanString(" @%s", endpoint.Version)
+}
+
+// formatTags lists the API documentation tags of an endpoint
+func formatTags(endpoint *models.Endpoint) string {
+	var tags string
+	for _, tag := range endpoint.Tags {
+		tags += " #" + tag
+	}
+	return color.MagentaString(tags)
+}
+
+// printAPIDocs prints the description and documented responses of an endpoint
+func printAPIDocs(endpoint *models.Endpoint) {
+	if endpoint.Description != "" {
+		color.HiBlack("        %s", endpoint.Description)
+	}
+
+	var responses []string
+	for _, response := range endpoint.Responses {
+		if response.Description != "" {
+			responses = append(responses, fmt.Sprintf("%d %s", response.Status, response.Description))
+		}
+	}
+	if len(responses) > 0 {
+		color.HiBlack("        responses: %s", strings.Join(responses, ", "))
+	}
+}
+
+// formatSchemas describes the request and response bodies of an endpoint
+func formatSchemas(endpoint *models.Endpoint) string {
+	if endpoint.Request == nil && endpoint.Response == nil {
+		return ""
+	}
+
+	request, response := "-", "-"
+	if endpoint.Request != nil {
+		request = endpoint.Request.Name
+	}
+	if endpoint.Response != nil {
+		response = endpoint.Response.Name
+	}
+	return color.HiBlackString(" (%s → %s)", request, response)
+}
+
+// formatParameters lists the query, header and cookie parameters and the
+// GraphQL arguments of an endpoint, marking required ones with "*"
+func formatParameters(endpoint *models.Endpoint) string {
+	var groups []string
+	for _, in := range []string{models.ParamQuery, models.ParamHeader, models.ParamCookie, models.ParamArgument} {
+		var names []string
+		for _, param := range endpoint.Parameters {
+			if param.In != in {
+				continue
+			}
+			name := param.Name
+			if param.Required {
+				name += "*"
+			}
+			if param.Default != "" {
+				name += "=" + param.Default
+			}
+			names = append(names, name)
+		}
+		if len(names) > 0 {
+			groups = append(groups, in+": "+strings.Join(names, ", "))
+		}
+	}
+
+	if len(groups) == 0 {
+		return ""
+	}
+	return color.HiBlackString(" [%s]", strings.Join(groups, "; "))
+}
+
+// formatStatusCodes lists the status codes an endpoint can respond with,
+// highlighting error codes
+func formatStatusCodes(endpoint *models.Endpoint) string {
+	if len(endpoint.StatusCodes) == 0 {
+		return ""
+	}
+
+	codes := make([]string, len(endpoint.StatusCodes))
+	for i, code := range endpoint.StatusCodes {
+		if code >= 400 {
+			codes[i] = color.RedString("%d", code)
+		} else {
+			codes[i] = color.GreenString("%d", code)
 		}
 	}
+	return " " + strings.Join(codes, " ")
+}
+
+// formatMiddleware lists the middleware and guards applied to an endpoint
+func formatMiddleware(endpoint *models.Endpoint) string {
+	if len(endpoint.Middleware) == 0 {
+		return ""
+	}
+	return color.HiBlackString(" ⛓ %s", strings.Join(endpoint.Middleware, ", "))
 }
 
 // colorizeMethodSimple returns a simple colored method string
@@ -94,3 +317,55 @@ func colorizeMethodSimple(method string) string {
 		return method
 	}
 }
+
+// FormatVersions prints how many endpoints each API version serves
+func FormatVersions(endpoints []*models.Endpoint) {
+	var versions []string
+	counts := make(map[string]int)
+	unversioned := 0
+
+	for _, endpoint := range endpoints {
+		if endpoint.Version == "" {
+			unversioned++
+			continue
+		}
+		for _, version := range strings.Split(endpoint.Version, ",") {
+			if _, ok := counts[version]; !ok {
+				versions = append(versions, version)
+			}
+			counts[version]++
+		}
+	}
+	if len(versions) == 0 {
+		return
+	}
+
+	sort.Strings(versions)
+	color.Green("\n🏷️  API versions:\n")
+	for _, version := range versions {
+		fmt.Printf("  %s: %d endpoints\n", color.CyanString(version), counts[version])
+	}
+	if unversioned > 0 {
+		fmt.Printf("  %s: %d endpoints\n", color.HiBlackString("unversioned"), unversioned)
+	}
+}
+
+// FormatConflicts prints route conflicts as warnings with both locations
+func FormatConflicts(conflicts []models.Conflict) {
+	if len(conflicts) == 0 {
+		return
+	}
+
+	color.Yellow("\n⚠️  Route conflicts (%d):\n", len(conflicts))
+	for _, conflict := range conflicts {
+		first, second := conflict.First, conflict.Second
+		switch conflict.Kind {
+		case models.ConflictShadowed:
+			color.Yellow("  • %s %s shadows %s %s", first.Method, first.Path, second.Method, second.Path)
+		default:
+			color.Yellow("  • %s %s is registered more than once", first.Method, first.Path)
+		}
+		color.HiBlack("      %s:%d", first.File, first.Line)
+		color.HiBlack("      %s:%d", second.File, second.Line)
+	}
+}
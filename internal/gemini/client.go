@@ -12,6 +12,9 @@ import (
 	"google.golang.org/api/option"
 )
 
+// batchSize is the number of endpoints summarized in a single request
+const batchSize = 5
+
 // Client wraps the Gemini API client
 type Client struct {
 	client *genai.Client
@@ -47,7 +50,6 @@ func (c *Client) Close() error {
 // SummarizeEndpoints processes multiple endpoints efficiently
 func (c *Client) SummarizeEndpoints(ctx context.Context, endpoints []*models.Endpoint) error {
 	// Group endpoints into batches for efficient processing
-	batchSize := 5 // Process 5 endpoints at once
 	batches := batchEndpoints(endpoints, batchSize)
 	
 	// Process batches concurrently with rate limiting
@@ -108,21 +110,34 @@ func (c *Client) summarizeBatch(ctx context.Context, endpoints []*models.Endpoin
 	return nil
 }
 
+// EstimateTokens approximates the prompt tokens sent to summarize the
+// endpoints, at about four characters per token
+func EstimateTokens(endpoints []*models.Endpoint) int {
+	chars := 0
+	for _, batch := range batchEndpoints(endpoints, batchSize) {
+		chars += len(buildBatchPrompt(batch))
+	}
+	return chars / 4
+}
+
 // buildBatchPrompt creates an optimized prompt for multiple endpoints
 func buildBatchPrompt(endpoints []*models.Endpoint) string {
 	var builder strings.Builder
 	
-	builder.WriteString("Analyze these REST API endpoints. For each, provide a one-line summary (max 50 chars).\n")
+	builder.WriteString("Analyze these API endpoints (REST unless marked GraphQL, WebSocket or gRPC). For each, provide a one-line summary (max 50 chars).\n")
 	builder.WriteString("Format: [N] Summary\n\n")
 	
 	for i, endpoint := range endpoints {
 		// Include only essential information to reduce tokens
-		builder.WriteString(fmt.Sprintf("[%d] %s %s\n", i+1, endpoint.Method, endpoint.Path))
+		kind := ""
+		if endpoint.Kind != "" && endpoint.Kind != models.KindREST {
+			kind = endpoint.Kind + " "
+		}
+		builder.WriteString(fmt.Sprintf("[%d] %s%s %s\n", i+1, kind, endpoint.Method, endpoint.Path))
 		
-		// Extract only the most relevant code lines
-		relevantCode := extractRelevantCode(endpoint.RawCode)
-		if relevantCode != "" {
-			builder.WriteString(fmt.Sprintf("Code: %s\n", relevantCode))
+		// The handler code is already trimmed to the context token budget
+		if endpoint.RawCode != "" {
+			builder.WriteString(fmt.Sprintf("Code:\n```\n%s\n```\n", endpoint.RawCode))
 		}
 		builder.WriteString("\n")
 	}
@@ -132,45 +147,6 @@ func buildBatchPrompt(endpoints []*models.Endpoint) string {
 	return builder.String()
 }
 
-// extractRelevantCode extracts only the most relevant part of the code
-func extractRelevantCode(rawCode string) string {
-	lines := strings.Split(rawCode, "\n")
-	var relevant []string
-	
-	// Look for function definitions, return statements, or key operations
-	keywords := []string{"def ", "function", "return", "create", "update", "delete", "get", "post", "find", "save"}
-	
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if trimmed == "" || strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "#") {
-			continue
-		}
-		
-		// Check if line contains relevant keywords
-		lower := strings.ToLower(trimmed)
-		for _, keyword := range keywords {
-			if strings.Contains(lower, keyword) {
-				relevant = append(relevant, trimmed)
-				if len(relevant) >= 3 { // Limit to 3 most relevant lines
-					break
-				}
-			}
-		}
-	}
-	
-	// If no relevant lines found, take the first non-empty line
-	if len(relevant) == 0 && len(lines) > 0 {
-		for _, line := range lines {
-			if trimmed := strings.TrimSpace(line); trimmed != "" {
-				relevant = append(relevant, trimmed)
-				break
-			}
-		}
-	}
-	
-	return strings.Join(relevant, "; ")
-}
-
 // parseBatchResponse extracts summaries from batch response
 func parseBatchResponse(resp *genai.GenerateContentResponse) []string {
 	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
@@ -0,0 +1,354 @@
+// Package gitfs exposes the tree of a git revision as an fs.FS, reading it
+// with the local git binary so that no working copy has to be checked out
+package gitfs
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FS is the read-only tree of a commit. Directory listings are built when
+// the revision is opened; file contents are read on demand.
+type FS struct {
+	repo    string
+	commit  string
+	modTime time.Time
+	entries map[string]*entry // Keyed by slash-separated path, "." for the root
+
+	mu     sync.Mutex // Serializes requests to the cat-file process
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// entry is a file or directory of the tree
+type entry struct {
+	name     string
+	dir      bool
+	size     int64
+	object   string   // Blob hash of a file
+	children []string // Sorted names of a directory's entries
+}
+
+// Root returns the top-level directory of the repository containing dir,
+// which need not exist in the working copy as long as one of its parents does
+func Root(dir string) (string, error) {
+	existing := dir
+	for {
+		if info, err := os.Stat(existing); err == nil && info.IsDir() {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return "", fmt.Errorf("%s is not inside a git repository", dir)
+		}
+		existing = parent
+	}
+
+	// Strip the path inside the repository rather than trusting
+	// --show-toplevel, which resolves symlinks in the path
+	out, err := git(existing, "rev-parse", "--show-prefix")
+	if err != nil {
+		return "", fmt.Errorf("%s is not inside a git repository: %w", dir, err)
+	}
+	root := existing
+	for _, segment := range strings.Split(strings.Trim(strings.TrimSpace(string(out)), "/"), "/") {
+		if segment != "" {
+			root = filepath.Dir(root)
+		}
+	}
+	return root, nil
+}
+
+// Open reads the tree of rev, any commit-ish such as a tag, branch or hash,
+// in the repository at repo
+func Open(repo, rev string) (*FS, error) {
+	out, err := git(repo, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("unknown revision %q", rev)
+	}
+	commit := strings.TrimSpace(string(out))
+
+	out, err = git(repo, "show", "-s", "--format=%ct", commit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", commit, err)
+	}
+	seconds, _ := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+
+	out, err = git(repo, "ls-tree", "-r", "-z", "--long", "--full-tree", commit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the tree of %s: %w", commit, err)
+	}
+
+	fsys := &FS{
+		repo:    repo,
+		commit:  commit,
+		modTime: time.Unix(seconds, 0),
+		entries: map[string]*entry{".": {name: ".", dir: true}},
+	}
+	for _, record := range bytes.Split(out, []byte{0}) {
+		// <mode> <type> <object> <size>\t<path>
+		meta, name, ok := strings.Cut(string(record), "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 4 || fields[1] != "blob" || fields[0] == "120000" {
+			// Submodules and symbolic links have no content of their own
+			continue
+		}
+		size, _ := strconv.ParseInt(fields[3], 10, 64)
+		fsys.add(name, &entry{name: path.Base(name), size: size, object: fields[2]})
+	}
+	for _, e := range fsys.entries {
+		sort.Strings(e.children)
+	}
+
+	return fsys, nil
+}
+
+// add inserts a file and the directories leading to it
+func (f *FS) add(name string, file *entry) {
+	f.entries[name] = file
+	for {
+		parent := path.Dir(name)
+		dir, ok := f.entries[parent]
+		if !ok {
+			dir = &entry{name: path.Base(parent), dir: true}
+			f.entries[parent] = dir
+		}
+		dir.children = append(dir.children, path.Base(name))
+		if ok || parent == "." {
+			return
+		}
+		name = parent
+	}
+}
+
+// Commit returns the hash of the commit the tree belongs to
+func (f *FS) Commit() string {
+	return f.commit
+}
+
+// Close stops the git process reading file contents
+func (f *FS) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.cmd == nil {
+		return nil
+	}
+	f.stdin.Close()
+	err := f.cmd.Wait()
+	f.cmd = nil
+	return err
+}
+
+// Open implements fs.FS
+func (f *FS) Open(name string) (fs.File, error) {
+	e, err := f.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if e.dir {
+		entries, _ := f.ReadDir(name)
+		return &dirFile{info: f.info(e), entries: entries}, nil
+	}
+
+	content, err := f.readBlob(e.object)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &file{Reader: bytes.NewReader(content), info: f.info(e)}, nil
+}
+
+// ReadFile implements fs.ReadFileFS
+func (f *FS) ReadFile(name string) ([]byte, error) {
+	e, err := f.lookup("read", name)
+	if err != nil {
+		return nil, err
+	}
+	if e.dir {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fmt.Errorf("is a directory")}
+	}
+	content, err := f.readBlob(e.object)
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	return content, nil
+}
+
+// Stat implements fs.StatFS
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	e, err := f.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return f.info(e), nil
+}
+
+// ReadDir implements fs.ReadDirFS
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	e, err := f.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !e.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fmt.Errorf("not a directory")}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(e.children))
+	for _, child := range e.children {
+		entries = append(entries, f.info(f.entries[path.Join(name, child)]))
+	}
+	return entries, nil
+}
+
+// lookup finds the entry of a path, reporting a missing one as op failing
+func (f *FS) lookup(op, name string) (*entry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	e, ok := f.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return e, nil
+}
+
+// readBlob returns the content of a blob from a long-running
+// git cat-file --batch process
+func (f *FS) readBlob(object string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.cmd == nil {
+		cmd := exec.Command("git", "-C", f.repo, "cat-file", "--batch")
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, fmt.Errorf("failed to start git cat-file: %w", err)
+		}
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, fmt.Errorf("failed to start git cat-file: %w", err)
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("failed to start git cat-file: %w", err)
+		}
+		f.cmd, f.stdin, f.stdout = cmd, stdin, bufio.NewReader(stdout)
+	}
+
+	if _, err := fmt.Fprintln(f.stdin, object); err != nil {
+		return nil, fmt.Errorf("failed to request blob %s: %w", object, err)
+	}
+
+	// <object> blob <size>\n<content>\n
+	header, err := f.stdout.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", object, err)
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("failed to read blob %s: %s", object, strings.TrimSpace(header))
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", object, err)
+	}
+
+	content := make([]byte, size+1)
+	if _, err := io.ReadFull(f.stdout, content); err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", object, err)
+	}
+	return content[:size], nil
+}
+
+// info describes an entry; every entry carries the commit time
+func (f *FS) info(e *entry) *fileInfo {
+	return &fileInfo{entry: e, modTime: f.modTime}
+}
+
+// git runs a git command in dir and returns its output
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// fileInfo implements fs.FileInfo and fs.DirEntry for an entry
+type fileInfo struct {
+	entry   *entry
+	modTime time.Time
+}
+
+func (i *fileInfo) Name() string       { return i.entry.name }
+func (i *fileInfo) Size() int64        { return i.entry.size }
+func (i *fileInfo) ModTime() time.Time { return i.modTime }
+func (i *fileInfo) IsDir() bool        { return i.entry.dir }
+func (i *fileInfo) Sys() any           { return nil }
+
+func (i *fileInfo) Mode() fs.FileMode {
+	if i.entry.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+func (i *fileInfo) Type() fs.FileMode          { return i.Mode().Type() }
+func (i *fileInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+// file is an open file of the tree
+type file struct {
+	*bytes.Reader
+	info *fileInfo
+}
+
+func (f *file) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *file) Close() error               { return nil }
+
+// dirFile is an open directory of the tree
+type dirFile struct {
+	info    *fileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *dirFile) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *dirFile) Close() error               { return nil }
+
+func (d *dirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.Name(), Err: fmt.Errorf("is a directory")}
+}
+
+// ReadDir implements fs.ReadDirFile
+func (d *dirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+	return rest[:n], nil
+}
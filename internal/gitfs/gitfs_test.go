@@ -0,0 +1,227 @@
+package gitfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// newRepo creates a repository with a v1 tag and a later commit that
+// changes and removes files
+func newRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_AUTHOR_DATE=2024-01-02T03:04:05Z", "GIT_COMMITTER_DATE=2024-01-02T03:04:05Z",
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q")
+	write("main.go", "package main\n")
+	write("api/routes.go", "package api\n\n// v1 routes\n")
+	write("api/v1/users.go", "package v1\n")
+	run("add", "-A")
+	run("commit", "-q", "-m", "v1")
+	run("tag", "v1")
+
+	write("api/routes.go", "package api\n\n// v2 routes\n")
+	if err := os.RemoveAll(filepath.Join(repo, "api", "v1")); err != nil {
+		t.Fatal(err)
+	}
+	run("add", "-A")
+	run("commit", "-q", "-m", "v2")
+
+	return repo
+}
+
+func TestOpen(t *testing.T) {
+	repo := newRepo(t)
+
+	fsys, err := Open(repo, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	if len(fsys.Commit()) != 40 {
+		t.Errorf("Commit() = %q, want a full hash", fsys.Commit())
+	}
+
+	// The tagged tree, not the working copy, is read
+	content, err := fsys.ReadFile("api/routes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "v1 routes") {
+		t.Errorf("api/routes.go = %q, want the tagged content", content)
+	}
+	if _, err := fsys.ReadFile("api/v1/users.go"); err != nil {
+		t.Errorf("file removed after the tag: %v", err)
+	}
+
+	entries, err := fsys.ReadDir("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if strings.Join(names, ",") != "routes.go,v1" || !entries[1].IsDir() {
+		t.Errorf("ReadDir(api) = %v, want routes.go and the v1 directory", names)
+	}
+
+	info, err := fsys.Stat("main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != int64(len("package main\n")) || info.IsDir() || info.ModTime().Unix() != 1704164645 {
+		t.Errorf("Stat(main.go) = size %d, dir %v, modified %v", info.Size(), info.IsDir(), info.ModTime())
+	}
+
+	if err := fstest.TestFS(fsys, "main.go", "api/routes.go", "api/v1/users.go"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOpenHead(t *testing.T) {
+	repo := newRepo(t)
+
+	fsys, err := Open(repo, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	content, err := fsys.ReadFile("api/routes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "v2 routes") {
+		t.Errorf("api/routes.go = %q, want the latest content", content)
+	}
+	if _, err := fsys.Stat("api/v1"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat(api/v1) error = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestOpenUnknownRevision(t *testing.T) {
+	repo := newRepo(t)
+
+	if _, err := Open(repo, "v9"); err == nil || !strings.Contains(err.Error(), `unknown revision "v9"`) {
+		t.Errorf("Open(v9) error = %v, want unknown revision", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	repo := newRepo(t)
+
+	fsys, err := Open(repo, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	tests := []struct {
+		name string
+		err  func() error
+		want error
+	}{
+		{"read missing", func() error { _, err := fsys.ReadFile("missing.go"); return err }, fs.ErrNotExist},
+		{"open missing", func() error { _, err := fsys.Open("api/missing.go"); return err }, fs.ErrNotExist},
+		{"stat missing", func() error { _, err := fsys.Stat("docs"); return err }, fs.ErrNotExist},
+		{"readdir missing", func() error { _, err := fsys.ReadDir("docs"); return err }, fs.ErrNotExist},
+		{"invalid path", func() error { _, err := fsys.ReadFile("../main.go"); return err }, fs.ErrInvalid},
+		{"read directory", func() error { _, err := fsys.ReadFile("api"); return err }, nil},
+		{"readdir file", func() error { _, err := fsys.ReadDir("main.go"); return err }, nil},
+	}
+
+	for _, tt := range tests {
+		err := tt.err()
+		if err == nil {
+			t.Errorf("%s: no error", tt.name)
+			continue
+		}
+		var pathErr *fs.PathError
+		if !errors.As(err, &pathErr) {
+			t.Errorf("%s: error %v is not a *fs.PathError", tt.name, err)
+		}
+		if tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestMissingObject(t *testing.T) {
+	repo := newRepo(t)
+
+	fsys, err := Open(repo, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	// An object listed in the tree but absent from the object store, as in
+	// a partial clone, fails the read without breaking later reads
+	fsys.entries["main.go"].object = strings.Repeat("0", 40)
+	if _, err := fsys.ReadFile("main.go"); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("ReadFile(main.go) error = %v, want a missing object", err)
+	}
+	if _, err := fsys.Open("main.go"); err == nil {
+		t.Error("Open(main.go) succeeded for a missing object")
+	}
+
+	content, err := fsys.ReadFile("api/routes.go")
+	if err != nil {
+		t.Fatalf("read after a missing object: %v", err)
+	}
+	if !strings.Contains(string(content), "v1 routes") {
+		t.Errorf("api/routes.go = %q after a missing object", content)
+	}
+}
+
+func TestRoot(t *testing.T) {
+	repo := newRepo(t)
+
+	// The directory only exists at the tagged revision
+	root, err := Root(filepath.Join(repo, "api", "v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := filepath.EvalSymlinks(repo)
+	got, _ := filepath.EvalSymlinks(root)
+	if got != want {
+		t.Errorf("Root = %s, want %s", root, repo)
+	}
+
+	if _, err := Root(t.TempDir()); err == nil {
+		t.Error("Root succeeded outside a repository")
+	}
+}
@@ -0,0 +1,50 @@
+package detector
+
+import (
+	"sync"
+
+	"github.com/tarantino19/restgo/pkg/models"
+)
+
+// File is a source file handed to a Detector
+type File struct {
+	Path         string            // Absolute path of the file
+	Content      []byte            // Raw file content
+	Dependencies map[string]string // Packages declared by the nearest manifests (lowercased name -> version)
+}
+
+// Detector discovers endpoints in source files. Built-in regex-based
+// discovery is one implementation; AST, file-path or config-file based
+// discovery can be plugged in by registering additional detectors.
+type Detector interface {
+	// Name identifies the detector in warnings
+	Name() string
+
+	// Supports reports whether the detector wants to inspect the file at path
+	Supports(path string) bool
+
+	// Detect returns the endpoints declared in files. The analyzer calls it
+	// once per directory with every supported file of that directory, and
+	// may call it concurrently for different directories.
+	Detect(files []File) ([]*models.Endpoint, error)
+}
+
+var (
+	mu       sync.RWMutex
+	registry []Detector
+)
+
+// Register adds a detector to the set used by every analysis.
+// It is typically called from an init function before the CLI runs.
+func Register(d Detector) {
+	mu.Lock()
+	defer mu.Unlock()
+	registry = append(registry, d)
+}
+
+// Registered returns the registered detectors in registration order
+func Registered() []Detector {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]Detector{}, registry...)
+}
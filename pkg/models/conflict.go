@@ -0,0 +1,14 @@
+package models
+
+// Conflict kinds reported by route conflict detection
+const (
+	ConflictDuplicate = "duplicate" // Same method and path registered by two handlers
+	ConflictShadowed  = "shadowed"  // An earlier parameterized route hides a later literal one
+)
+
+// Conflict describes two endpoints whose routes collide
+type Conflict struct {
+	Kind   string    // ConflictDuplicate or ConflictShadowed
+	First  *Endpoint // Endpoint registered first
+	Second *Endpoint // Endpoint that collides with First
+}
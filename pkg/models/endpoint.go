@@ -1,14 +1,53 @@
 package models
 
-// Endpoint represents a REST API endpoint
+// Summary sources
+const (
+	SummaryFromDocs = "docs" // Taken from a docstring, doc comment or annotation
+	SummaryFromAI   = "ai"   // Generated by Gemini
+)
+
+// Endpoint sources
+const (
+	SourceCode   = "code"   // Found in source code
+	SourceSpec   = "spec"   // Imported from an OpenAPI or Swagger document
+	SourceConfig = "config" // Declared in a serverless or API gateway config file
+)
+
+// Endpoint kinds
+const (
+	KindREST      = "rest"      // HTTP route
+	KindGraphQL   = "graphql"   // GraphQL endpoint or schema operation
+	KindWebSocket = "websocket" // WebSocket upgrade route or message handler
+	KindGRPC      = "grpc"      // gRPC method declared in a .proto file
+)
+
+// Endpoint represents an API endpoint: a REST route, a GraphQL operation,
+// a WebSocket route or message, or a gRPC method
 type Endpoint struct {
-	Method      string // HTTP method (GET, POST, PUT, DELETE, etc.)
-	Path        string // Endpoint path (e.g., /users/:id)
-	File        string // Source file where endpoint is defined
-	Line        int    // Line number in source file
-	Function    string // Function/handler name
-	Summary     string // AI-generated summary
-	Language    string // Programming language
-	Framework   string // Web framework used
-	RawCode     string // Raw code snippet for context
-} 
\ No newline at end of file
+	Kind             string      // KindREST, KindGraphQL, KindWebSocket or KindGRPC
+	Source           string      // SourceCode, SourceSpec or SourceConfig
+	Method           string      // HTTP method (GET, POST, ...), or QUERY/MUTATION/SUBSCRIPTION, EVENT, RPC
+	Path             string      // Endpoint path (e.g., /users/:id), operation, event or /package.Service/Method
+	PublicURL        string      // Externally visible URL after Ingress and reverse-proxy rewrites, if known
+	File             string      // Source file where endpoint is defined
+	Line             int         // Line number in source file
+	Function         string      // Function/handler name
+	Summary          string      // Summary from the handler's docs or generated by AI
+	SummarySource    string      // SummaryFromDocs or SummaryFromAI
+	Description      string      // Longer description from API documentation annotations
+	Tags             []string    // API documentation tags grouping the endpoint
+	Language         string      // Programming language
+	Framework        string      // Web framework used
+	FrameworkVersion string      // Framework version declared in the project manifest
+	Service          string      // Service (monorepo project root) the endpoint belongs to
+	Test             bool        // Whether the route is registered in a test file
+	Version          string      // API version, e.g. v2 or 2024-06-01; comma-separated when served in several
+	RawCode          string      // Raw code snippet for context
+	Request          *Schema     // Request body schema, if declared
+	Response         *Schema     // Response body schema, if declared
+	Parameters       []Parameter // Path, query, header and cookie parameters
+	Middleware       []string    // Middleware, guards and auth annotations applied to the endpoint
+	AuthRequired     bool        // Whether an auth middleware or guard protects the endpoint
+	StatusCodes      []int       // Status codes the handler can respond with
+	Responses        []Response  // Responses documented by API annotations
+}
@@ -0,0 +1,21 @@
+package models
+
+// Parameter locations
+const (
+	ParamPath   = "path"
+	ParamQuery  = "query"
+	ParamHeader = "header"
+	ParamCookie = "cookie"
+
+	ParamArgument = "argument" // GraphQL field argument
+)
+
+// Parameter is an input read by an endpoint outside of its body
+type Parameter struct {
+	Name        string // Parameter name (e.g. page, X-Request-ID)
+	In          string // ParamPath, ParamQuery, ParamHeader, ParamCookie or ParamArgument
+	Type        string // Declared type, if known
+	Required    bool   // Whether the request must provide it
+	Default     string // Default value as written in source, if any
+	Description string // Documented meaning of the parameter, if any
+}
@@ -0,0 +1,21 @@
+package models
+
+// Schema describes the body of a request or response
+type Schema struct {
+	Name   string  // Type name as written in source (e.g. CreateUserRequest, List[User])
+	Fields []Field // Fields of the type, empty when its declaration was not found
+}
+
+// Field is a single property of a Schema
+type Field struct {
+	Name     string // Name on the wire (e.g. from a json tag)
+	Type     string // Declared type as written in source
+	Required bool   // Whether the field must be present
+}
+
+// Response is a documented response of an endpoint
+type Response struct {
+	Status      int    // HTTP status code
+	Description string // Documented meaning of the response
+	Schema      string // Documented body type, if any
+}